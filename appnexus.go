@@ -2,6 +2,7 @@ package appnexus
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-querystring/query"
@@ -24,14 +26,35 @@ type credentials struct {
 
 // Client used to make HTTP requests
 type Client struct {
-	client      *http.Client
-	EndPoint    *url.URL
-	Rate        Rate
-	UserAgent   string
-	token       string
-	credentials credentials
-	MemberID    int
-	iterations  int
+	client     *http.Client
+	EndPoint   *url.URL
+	Rate       Rate
+	UserAgent  string
+	MemberID   int
+	iterations int
+
+	// auth is the login state, shared with the client's member views
+	auth *session
+
+	// memberScoped is set on views returned by ForMember, whose requests
+	// all carry member_id
+	memberScoped bool
+
+	// DryRun captures POST, PUT and DELETE requests into the plan returned
+	// by Planned instead of sending them. GET requests and logins still go
+	// through.
+	DryRun    bool
+	planned   []PlannedRequest
+	dryRunIDs int64
+
+	// SafeUpdates makes every Update re-fetch the object first and fail
+	// with a *ConflictError if its last_modified differs from the caller's
+	SafeUpdates bool
+
+	// Validate checks objects against the meta of their service before
+	// Add, Update and Patch send them, failing with a *ValidationError
+	// that lists the rejected fields
+	Validate bool
 
 	Members    *MemberService
 	Segments   *SegmentService
@@ -39,6 +62,25 @@ type Client struct {
 	Sites      *SiteService
 	Placements *PlacementService
 	Deals      *DealService
+
+	DomainLists    *DomainListService
+	InventoryLists *InventoryListService
+	PaymentRules   *PaymentRuleService
+	Lookups        *LookupService
+	ChangeLogs     *ChangeLogService
+}
+
+// session is the login state of a client. It is shared by pointer between a
+// client and its ForMember views, so a re-login or user switch made through
+// one is seen by all of them.
+type session struct {
+	credentials credentials
+	token       string
+
+	// loginToken is the token of the login user while switchedUser is
+	// being acted as, token then being the switched session's
+	loginToken   string
+	switchedUser int
 }
 
 // Rate contains information on the current rate limit in operation
@@ -55,28 +97,96 @@ type Rate struct {
 type Response struct {
 	*http.Response
 	Obj struct {
-		Status           string      `json:"status"`
-		ID               json.Number `json:"id,omitempty,Number"`
-		ErrorID          string      `json:"error_id,omitempty"`
-		Error            string      `json:"error,omitempty"`
-		ErrorDescription string      `json:"error_description,omitempty"`
-		ErrorCode        string      `json:"error_code,omitempty"`
-		Token            string      `json:"token,omitempty"`
-		Service          string      `json:"service,omitempty"`
-		Method           string      `json:"method,omitempty"`
-		Count            int         `json:"count,omitempty"`
-		StartElement     int         `json:"start_element,omitempty"`
-		NumElements      int         `json:"num_elements,omitempty"`
-		Deal             Deal        `json:"deal,omitempty"`
-		Placement        Placement   `json:"placement,omitempty"`
-		Site             Site        `json:"site,omitempty"`
-		Publisher        Publisher   `json:"publisher,omitempty"`
-		Member           Member      `json:"member,omitempty"`
-		Segments         []Segment   `json:"segments,omitempty"`
-		Rate             Rate        `json:"dbg_info"`
+		responseStatus
+		Deal      Deal      `json:"deal,omitempty"`
+		Placement Placement `json:"placement,omitempty"`
+		Site      Site      `json:"site,omitempty"`
+		Publisher Publisher `json:"publisher,omitempty"`
+		Member    Member    `json:"member,omitempty"`
+		Segments  []Segment `json:"segments,omitempty"`
+	} `json:"response"`
+}
+
+// responseStatus holds the fields every API response carries besides its
+// objects, which is all do decodes into the returned *Response when the
+// caller decodes the objects into a typed response of its own. Typed
+// responses embed it in their response object so that the status and the
+// objects are decoded together.
+type responseStatus struct {
+	Status           string      `json:"status"`
+	ID               json.Number `json:"id,omitempty,Number"`
+	ErrorID          string      `json:"error_id,omitempty"`
+	Error            string      `json:"error,omitempty"`
+	ErrorDescription string      `json:"error_description,omitempty"`
+	ErrorCode        string      `json:"error_code,omitempty"`
+	Token            string      `json:"token,omitempty"`
+	Service          string      `json:"service,omitempty"`
+	Method           string      `json:"method,omitempty"`
+	Count            int         `json:"count,omitempty"`
+	StartElement     int         `json:"start_element,omitempty"`
+	NumElements      int         `json:"num_elements,omitempty"`
+	Rate             Rate        `json:"dbg_info"`
+}
+
+// statusResponse is a response decoded for its status fields only
+type statusResponse struct {
+	Obj struct {
+		responseStatus
 	} `json:"response"`
 }
 
+// statusType is the type typed responses embed for the status fields
+var statusType = reflect.TypeFor[responseStatus]()
+
+// embeddedStatus returns the status fields embedded in the response object,
+// Obj, of the typed response v, or nil if v does not embed them
+func embeddedStatus(v interface{}) *responseStatus {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	obj := rv.Elem().FieldByName("Obj")
+	if obj.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < obj.NumField(); i++ {
+		if f := obj.Type().Field(i); f.Anonymous && f.Type == statusType {
+			// the embedded field may be unexported, which rules out
+			// Interface
+			return (*responseStatus)(obj.Field(i).Addr().UnsafePointer())
+		}
+	}
+
+	return nil
+}
+
+// APIError is an error reported in the body of an API response, such as
+// NOTFOUND or NOAUTH in ErrorID. Errors returned by the client wrap it, so it
+// is matched with errors.As.
+type APIError struct {
+	ErrorID     string
+	Message     string
+	Description string
+	Code        string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("AppNexus:checkResponse [%s]: %s", e.ErrorID, e.Message)
+}
+
+// PlannedRequest is a write request captured by the client in dry-run mode
+type PlannedRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// dryRunIDBase is the first synthetic ID handed out for objects added in
+// dry-run mode, chosen to be well clear of real AppNexus IDs
+const dryRunIDBase = 1000000000000
+
 // ListOptions specifies the optional parameters to various List methods that
 // support pagination.
 type ListOptions struct {
@@ -98,18 +208,73 @@ func NewClient(endPointURL string) (*Client, error) {
 		client:    httpClient,
 		EndPoint:  baseURL,
 		UserAgent: "github.com/tnako/appnexus go-appnexus-client",
+		auth:      &session{},
 	}
 
-	c.Members = &MemberService{client: c}
-	c.Segments = &SegmentService{client: c}
-	c.Publishers = &PublisherService{client: c}
-	c.Sites = &SiteService{client: c}
-	c.Placements = &PlacementService{client: c}
-	c.Deals = &DealService{client: c}
+	c.initServices(NewMemoryCache(defaultLookupTTL))
 
 	return c, nil
 }
 
+// initServices points a fresh set of services at the client
+func (c *Client) initServices(cache LookupCache) {
+	c.Members = &MemberService{client: c}
+	c.Segments = &SegmentService{client: c, svc: NewService[Segment](c, "segment", "segments", segmentPath)}
+	c.Publishers = &PublisherService{client: c, svc: NewService[Publisher](c, "publisher", "publishers", nil)}
+	c.Sites = &SiteService{client: c, svc: NewService[Site](c, "site", "sites", nil)}
+	c.Placements = &PlacementService{client: c, svc: NewService[Placement](c, "placement", "placements", nil)}
+	c.Deals = &DealService{client: c, svc: NewService[Deal](c, "deal", "deals", nil)}
+	c.DomainLists = &DomainListService{client: c, svc: NewService[DomainList](c, "domain-list", "domain-lists", nil)}
+	c.InventoryLists = newInventoryListService(c)
+	c.PaymentRules = &PaymentRuleService{client: c, svc: NewService[PaymentRule](c, "payment-rule", "payment-rules", nil)}
+	c.Lookups = &LookupService{client: c, Cache: cache}
+	c.ChangeLogs = newChangeLogService(c)
+}
+
+// ForMember returns a view of the client acting on behalf of the member, for
+// network and reseller logins which manage several members. Every request
+// made through the view's services carries member_id, its MemberID is the
+// member, and segment methods given a zero member ID use it. The view shares
+// the client's login session, so a re-login or SwitchUser through either is
+// seen by both, as well as its HTTP client and lookup cache, but keeps its
+// own rate information and dry-run plan.
+func (c *Client) ForMember(memberID int) *Client {
+	view := *c
+	view.MemberID = memberID
+	view.memberScoped = true
+	view.planned = nil
+	view.initServices(c.Lookups.Cache)
+
+	return &view
+}
+
+// SwitchMember returns a view of the client acting on behalf of the member
+// as userID, one of the member's users, the way the API switches a network
+// login into a member. Unlike ForMember the view has a session of its own:
+// it logs in again with the client's credentials and switches to the user,
+// leaving the client and its other views acting as the login user.
+func (c *Client) SwitchMember(memberID, userID int) (*Client, error) {
+	if memberID < 1 {
+		return nil, errors.New("SwitchMember requires a member ID")
+	}
+
+	if c.auth.credentials.Username == "" {
+		return nil, errors.New("SwitchMember requires the client to have logged in")
+	}
+
+	view := c.ForMember(memberID)
+	view.auth = &session{}
+	if err := view.Login(c.auth.credentials.Username, c.auth.credentials.Password); err != nil {
+		return nil, err
+	}
+
+	if err := view.SwitchUser(userID); err != nil {
+		return nil, err
+	}
+
+	return view, nil
+}
+
 // NewRequest creates an API request using a relative URL
 func (c *Client) newRequest(method, path string, body interface{}) (*http.Request, error) {
 	c.iterations = 0
@@ -121,6 +286,14 @@ func (c *Client) newRequest(method, path string, body interface{}) (*http.Reques
 
 	u := c.EndPoint.ResolveReference(rel)
 
+	if c.memberScoped && rel.Path != "auth" {
+		params := u.Query()
+		if params.Get("member_id") == "" {
+			params.Set("member_id", strconv.Itoa(c.MemberID))
+			u.RawQuery = params.Encode()
+		}
+	}
+
 	var buf io.ReadWriter
 	if body != nil {
 		buf = new(bytes.Buffer)
@@ -137,8 +310,8 @@ func (c *Client) newRequest(method, path string, body interface{}) (*http.Reques
 
 	req.Header.Add("User-Agent", c.UserAgent)
 
-	if c.token != "" {
-		req.Header.Add("Authorization", c.token)
+	if c.auth.token != "" {
+		req.Header.Add("Authorization", c.auth.token)
 	}
 
 	return req, nil
@@ -148,7 +321,11 @@ func (c *Client) newRequest(method, path string, body interface{}) (*http.Reques
 // JSON decoded and stored in the value pointed to by v, or returned as an
 // error if an API error has occurred.  If v implements the io.Writer
 // interface, the raw response body will be written to v, without attempting to
-// first decode it.
+// first decode it; only HTTP errors are reported then.  The body is decoded
+// with a single json.Decoder.Decode as it is read when the response object of
+// v embeds the status fields, as *Response and the typed responses of the
+// services do.  If v is a *Response it is returned, otherwise the returned
+// *Response only holds the status fields.
 func (c *Client) do(req *http.Request, v interface{}) (*Response, error) {
 
 	c.iterations++
@@ -156,7 +333,13 @@ func (c *Client) do(req *http.Request, v interface{}) (*Response, error) {
 		return nil, errors.New("client.do.do: Max retry iterations exceed 10")
 	}
 
-	_ = c.waitForRateLimit(req.Method)
+	if c.DryRun && req.Method != "GET" && !strings.HasSuffix(req.URL.Path, "/auth") {
+		return c.capture(req, v)
+	}
+
+	if _, err := c.waitForRateLimit(req.Context(), req.Method); err != nil {
+		return nil, err
+	}
 
 	var body []byte
 	var err error
@@ -171,7 +354,7 @@ func (c *Client) do(req *http.Request, v interface{}) (*Response, error) {
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, errors.New("client.do.do: " + err.Error())
+		return nil, fmt.Errorf("client.do.do: %w", err)
 	}
 
 	if resp.StatusCode == 429 {
@@ -187,7 +370,11 @@ func (c *Client) do(req *http.Request, v interface{}) (*Response, error) {
 				retr = 200
 			}
 			retr++ // APN glitch
-			time.Sleep(time.Duration(retr) * time.Second)
+			resp.Body.Close()
+
+			if err := sleep(req.Context(), time.Duration(retr)*time.Second); err != nil {
+				return nil, err
+			}
 
 			if body != nil {
 				req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
@@ -199,32 +386,102 @@ func (c *Client) do(req *http.Request, v interface{}) (*Response, error) {
 
 	defer resp.Body.Close()
 
-	data, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, errors.New("client.do.readall: " + err.Error())
+	if w, ok := v.(io.Writer); ok {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return nil, fmt.Errorf("client.do.checkResponse: %s | %#v", resp.Status, resp.Header)
+		}
+
+		if _, err := io.Copy(w, resp.Body); err != nil {
+			return nil, errors.New("client.do.copy: " + err.Error())
+		}
+
+		return &Response{Response: resp}, nil
 	}
 
-	response, err := c.checkResponse(resp, data)
+	response, err := c.parseResponse(resp, resp.Body, v)
 	if err != nil {
 
 		// If the call failed with a NOAUTH error, attempt to reauthenticate
-		// and try the request again:
-		if response != nil && req != nil && response.Obj.ErrorID == "NOAUTH" && req.URL.Path != "auth" {
-			c.token = ""
-			err = c.Login(c.credentials.Username, c.credentials.Password)
+		// and try the request again, switching back to the user the
+		// session had switched to:
+		if response != nil && req != nil && response.Obj.ErrorID == "NOAUTH" && !strings.HasSuffix(req.URL.Path, "/auth") {
+			switchedUser := c.auth.switchedUser
+			c.auth.token = ""
+			err = c.Login(c.auth.credentials.Username, c.auth.credentials.Password)
 			if err != nil {
 				return nil, errors.New("Could not reauthenticate:\n" + err.Error())
 			}
 
+			if switchedUser != 0 {
+				if err := c.SwitchUser(switchedUser); err != nil {
+					return nil, errors.New("Could not switch back to user:\n" + err.Error())
+				}
+			}
+
+			req.Header.Set("Authorization", c.auth.token)
+			if body != nil {
+				req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+			}
+
 			return c.do(req, v)
 		}
 
-		return nil, errors.New("client.do.checkResponse: " + err.Error())
+		return nil, fmt.Errorf("client.do.checkResponse: %w", err)
 	}
 
-	if v != nil {
-		err := json.Unmarshal(data, v)
+	// drain what the decoder left so the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	return response, nil
+}
+
+// capture records a write request in the dry-run plan and answers it with a
+// synthetic response, handing out a fresh ID for POSTs so that follow-up
+// calls can refer to the new object
+func (c *Client) capture(req *http.Request, v interface{}) (*Response, error) {
+	planned := PlannedRequest{Method: req.Method, URL: req.URL.String()}
+
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
 		if err != nil {
+			return nil, errors.New("client.do.body: " + err.Error())
+		}
+		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+		planned.Body = bytes.TrimSpace(body)
+	}
+
+	c.planned = append(c.planned, planned)
+
+	obj := map[string]interface{}{"status": "OK"}
+	if req.Method == "POST" {
+		c.dryRunIDs++
+		obj["id"] = dryRunIDBase + c.dryRunIDs
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"response": obj})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}
+
+	response := &Response{Response: resp}
+	if err := json.Unmarshal(data, response); err != nil {
+		return nil, errors.New("client.do.unmarshal: " + err.Error())
+	}
+
+	if w, ok := v.(io.Writer); ok {
+		if _, err := w.Write(data); err != nil {
+			return nil, errors.New("client.do.copy: " + err.Error())
+		}
+	} else if v != nil {
+		if err := json.Unmarshal(data, v); err != nil {
 			return nil, errors.New("client.do.unmarshal: " + err.Error())
 		}
 	}
@@ -232,8 +489,20 @@ func (c *Client) do(req *http.Request, v interface{}) (*Response, error) {
 	return response, nil
 }
 
-// Wait for the Write or Read rate limit timeout
-func (c *Client) waitForRateLimit(method string) time.Duration {
+// Planned returns the write requests captured so far in dry-run mode
+func (c *Client) Planned() []PlannedRequest {
+	planned := make([]PlannedRequest, len(c.planned))
+	copy(planned, c.planned)
+	return planned
+}
+
+// ResetPlan discards the write requests captured in dry-run mode
+func (c *Client) ResetPlan() {
+	c.planned = nil
+}
+
+// Wait for the Write or Read rate limit timeout, or until ctx is done
+func (c *Client) waitForRateLimit(ctx context.Context, method string) (time.Duration, error) {
 
 	var duration time.Duration
 
@@ -254,33 +523,95 @@ func (c *Client) waitForRateLimit(method string) time.Duration {
 	// More actions than the limit on the requested operation:
 	if actions >= limit {
 		duration = time.Duration(period) * time.Second
-		time.Sleep(duration)
+		if err := sleep(ctx, duration); err != nil {
+			return duration, err
+		}
 	}
 
-	return duration
+	return duration, nil
+}
+
+// sleep waits for d, returning the error of ctx if it is done first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 // CheckResponse checks the API response for errors, and returns them if
 // present.
 func (c *Client) checkResponse(r *http.Response, data []byte) (*Response, error) {
-	var resp *Response
+	return c.parseResponse(r, bytes.NewReader(data), nil)
+}
+
+// parseResponse checks a response for HTTP and API errors, decoding body
+// into v and its status fields into the returned *Response, which is v itself
+// if it is a *Response. If the response object of v embeds the status
+// fields, the body is decoded with a single json.Decoder.Decode as it is
+// read; otherwise, as for a *RawResponse, it is read in full and decoded
+// twice. An empty body returns no response.
+func (c *Client) parseResponse(r *http.Response, body io.Reader, v interface{}) (*Response, error) {
 	if r.StatusCode < 200 || r.StatusCode > 299 {
 		return nil, fmt.Errorf("%s | %#v", r.Status, r.Header)
 	}
 
-	if len(data) > 0 {
+	target := v
+	if target == nil {
+		target = &statusResponse{}
+	}
+
+	status := embeddedStatus(target)
+	if status != nil {
+		// a retried call decodes into the same response again
+		*status = responseStatus{}
 
-		resp = &Response{Response: r}
-		err := json.Unmarshal(data, resp)
+		err := json.NewDecoder(body).Decode(target)
+		if err == io.EOF {
+			return nil, nil
+		}
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			return nil, nil
+		}
+
+		statusOnly := &statusResponse{}
+		if err := json.Unmarshal(data, statusOnly); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, target); err != nil {
+			return nil, err
+		}
+		status = &statusOnly.Obj.responseStatus
+	}
 
-		c.Rate = resp.Obj.Rate
+	resp, ok := v.(*Response)
+	if !ok {
+		resp = &Response{}
+		resp.Obj.responseStatus = *status
+	}
+	resp.Response = r
 
-		if resp.Obj.ErrorID != "" || resp.Obj.Error != "" {
-			str := fmt.Sprintf("AppNexus:checkResponse [%s]: %s", resp.Obj.ErrorID, resp.Obj.Error)
-			return resp, errors.New(str)
+	c.Rate = resp.Obj.Rate
+
+	if resp.Obj.ErrorID != "" || resp.Obj.Error != "" {
+		return resp, &APIError{
+			ErrorID:     resp.Obj.ErrorID,
+			Message:     resp.Obj.Error,
+			Description: resp.Obj.ErrorDescription,
+			Code:        resp.Obj.ErrorCode,
 		}
 	}
 
@@ -290,15 +621,56 @@ func (c *Client) checkResponse(r *http.Response, data []byte) (*Response, error)
 // Login to the AppNexus API and get an authentication token
 func (c *Client) Login(username string, password string) error {
 
-	c.credentials = credentials{
+	c.auth.credentials = credentials{
 		Username: username,
 		Password: password,
 	}
 
 	auth := struct {
 		credentials `json:"auth"`
-	}{c.credentials}
+	}{c.auth.credentials}
+
+	req, err := c.newRequest("POST", "auth", auth)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req, nil)
+	if err != nil {
+		return err
+	}
+
+	c.auth.token = resp.Cookies()[0].Value
+	c.auth.loginToken = ""
+	c.auth.switchedUser = 0
+	return nil
+}
+
+// SwitchUser starts acting as another user, as network users may for the
+// users of their members. Every request is made as that user until
+// RestoreUser is called; the switch is re-established when the client logs
+// in again after its token expires.
+func (c *Client) SwitchUser(userID int) error {
+	if userID < 1 {
+		return errors.New("SwitchUser requires a user ID")
+	}
 
+	loginToken := c.auth.token
+	if c.auth.switchedUser != 0 {
+		loginToken = c.auth.loginToken
+	}
+	if loginToken == "" {
+		return errors.New("SwitchUser requires the client to be logged in")
+	}
+
+	auth := struct {
+		Auth struct {
+			SwitchToUser int `json:"switch_to_user"`
+		} `json:"auth"`
+	}{}
+	auth.Auth.SwitchToUser = userID
+
+	c.auth.token = loginToken
 	req, err := c.newRequest("POST", "auth", auth)
 	if err != nil {
 		return err
@@ -306,13 +678,53 @@ func (c *Client) Login(username string, password string) error {
 
 	resp, err := c.do(req, nil)
 	if err != nil {
+		c.RestoreUser()
 		return err
 	}
 
-	c.token = resp.Cookies()[0].Value
+	token := resp.Obj.Token
+	if token == "" {
+		if cookies := resp.Cookies(); len(cookies) > 0 {
+			token = cookies[0].Value
+		}
+	}
+	if token == "" {
+		c.RestoreUser()
+		return errors.New("SwitchUser: no token in response")
+	}
+
+	c.auth.loginToken = loginToken
+	c.auth.token = token
+	c.auth.switchedUser = userID
 	return nil
 }
 
+// RestoreUser ends a switched session started by SwitchUser and acts as the
+// login user again
+func (c *Client) RestoreUser() {
+	if c.auth.loginToken != "" {
+		c.auth.token = c.auth.loginToken
+	}
+
+	c.auth.loginToken = ""
+	c.auth.switchedUser = 0
+}
+
+// SwitchedUser returns the user being acted as, or zero
+func (c *Client) SwitchedUser() int {
+	return c.auth.switchedUser
+}
+
+// idList joins IDs with commas for the bulk forms of the id parameter
+func idList(ids []int64) string {
+	list := make([]string, len(ids))
+	for i, id := range ids {
+		list[i] = strconv.FormatInt(id, 10)
+	}
+
+	return strings.Join(list, ",")
+}
+
 // addOptions adds the parameters in opt as URL query parameters to s.  opt
 // must be a struct whose fields may contain "url" tags.
 func addOptions(s string, opt interface{}) (string, error) {
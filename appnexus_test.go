@@ -2,7 +2,7 @@ package appnexus
 
 import (
 	"bytes"
-	"errors"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -89,10 +89,14 @@ func TestCheckResponse(t *testing.T) {
 		t.Errorf("Expected error response")
 	}
 
-	expected := errors.New("AppNexus:checkResponse [SYNTAX]: invalid service")
+	expected := &APIError{ErrorID: "SYNTAX", Message: "invalid service"}
 	if !reflect.DeepEqual(err, expected) {
 		t.Errorf("Error = %#v, expected %#v", err, expected)
 	}
+
+	if msg := "AppNexus:checkResponse [SYNTAX]: invalid service"; err.Error() != msg {
+		t.Errorf("Error reads %q, expected %q", err.Error(), msg)
+	}
 }
 
 func TestWaitForRateLimit(t *testing.T) {
@@ -105,24 +109,32 @@ func TestWaitForRateLimit(t *testing.T) {
 	c.Rate.WriteLimitSeconds = 2
 	c.Rate.Writes = 0
 
-	if actual, expected := fmt.Sprintf("%.0f", c.waitForRateLimit("GET").Seconds()), "0"; actual != expected {
+	wait := func(method string) float64 {
+		d, err := c.waitForRateLimit(context.Background(), method)
+		if err != nil {
+			t.Errorf("waitForRateLimit returned error: %v", err)
+		}
+		return d.Seconds()
+	}
+
+	if actual, expected := fmt.Sprintf("%.0f", wait("GET")), "0"; actual != expected {
 		t.Errorf("Waited %v for read rate limit, expected %v", actual, expected)
 	}
 
 	c.Rate.Reads = 100
-	if actual, expected := fmt.Sprintf("%.0f", c.waitForRateLimit("GET").Seconds()), "2"; actual != expected {
+	if actual, expected := fmt.Sprintf("%.0f", wait("GET")), "2"; actual != expected {
 		t.Errorf("Waited %v for read rate limit, expected %v", actual, expected)
 	}
 
-	if actual, expected := fmt.Sprintf("%.0f", c.waitForRateLimit("POST").Seconds()), "0"; actual != expected {
+	if actual, expected := fmt.Sprintf("%.0f", wait("POST")), "0"; actual != expected {
 		t.Errorf("Waited %v for write rate limit, expected %v", actual, expected)
 	}
 
-	if actual, expected := fmt.Sprintf("%.0f", c.waitForRateLimit("PUT").Seconds()), "0"; actual != expected {
+	if actual, expected := fmt.Sprintf("%.0f", wait("PUT")), "0"; actual != expected {
 		t.Errorf("Waited %v for write rate limit, expected %v", actual, expected)
 	}
 
-	if actual, expected := fmt.Sprintf("%.0f", c.waitForRateLimit("DELETE").Seconds()), "0"; actual != expected {
+	if actual, expected := fmt.Sprintf("%.0f", wait("DELETE")), "0"; actual != expected {
 		t.Errorf("Waited %v for write rate limit, expected %v", actual, expected)
 	}
 }
@@ -160,3 +172,121 @@ func TestLimitResponse(t *testing.T) {
 		t.Errorf("retry pause didnt work")
 	}
 }
+
+func TestDryRun(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/segment/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("dry run sent a %s request", r.Method)
+		}
+		fmt.Fprint(w, `{"response":{"status":"OK","segment":{"id":5,"short_name":"live"}}}`)
+	})
+
+	client.DryRun = true
+
+	segment := &Segment{ShortName: "planned"}
+	if _, err := client.Segments.Add(1, segment); err != nil {
+		t.Fatalf("Segments.Add returned error: %v", err)
+	}
+
+	if segment.ID != dryRunIDBase+1 {
+		t.Errorf("Segments.Add set synthetic ID %d, expected %d", segment.ID, dryRunIDBase+1)
+	}
+
+	segment.Code = "planned"
+	if _, err := client.Segments.Update(1, *segment); err != nil {
+		t.Fatalf("Segments.Update returned error: %v", err)
+	}
+
+	live, err := client.Segments.Get(1, 5)
+	if err != nil || live.ShortName != "live" {
+		t.Errorf("Segments.Get returned %+v, %v", live, err)
+	}
+
+	planned := client.Planned()
+	if len(planned) != 2 {
+		t.Fatalf("Planned returned %d requests, expected 2", len(planned))
+	}
+
+	if actual, expected := planned[1].Method+" "+planned[1].URL, fmt.Sprintf("PUT %s/segment/1?id=%d", server.URL, segment.ID); actual != expected {
+		t.Errorf("Planned request is %v, expected %v", actual, expected)
+	}
+
+	if !strings.Contains(string(planned[0].Body), `"short_name":"planned"`) {
+		t.Errorf("Planned body is %s", planned[0].Body)
+	}
+
+	client.ResetPlan()
+	if len(client.Planned()) != 0 {
+		t.Errorf("ResetPlan left %v", client.Planned())
+	}
+}
+
+func TestSwitchUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	logins, switches := 0, 0
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "switch_to_user") {
+			switches++
+			if r.Header.Get("Authorization") != fmt.Sprintf("login-%d", logins) {
+				t.Errorf("switch sent with token %q", r.Header.Get("Authorization"))
+			}
+			fmt.Fprintf(w, `{"response":{"status":"OK","token":"switched-%d"}}`, switches)
+			return
+		}
+
+		logins++
+		http.SetCookie(w, &http.Cookie{Name: "authn", Value: fmt.Sprintf("login-%d", logins)})
+		fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+	})
+
+	var tokens []string
+	mux.HandleFunc("/publisher", func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		tokens = append(tokens, token)
+		if token == "switched-1" && len(tokens) > 1 {
+			fmt.Fprint(w, `{"response":{"error_id":"NOAUTH","error":"token expired"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"response":{"status":"OK","publisher":{"id":2}}}`)
+	})
+
+	if err := client.Login("network", "secret"); err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+
+	if err := client.SwitchUser(42); err != nil {
+		t.Fatalf("SwitchUser returned error: %v", err)
+	}
+
+	// The second call finds the switched token expired, logs in again and
+	// switches back before retrying
+	for i := 0; i < 2; i++ {
+		if _, err := client.Publishers.Get(2); err != nil {
+			t.Fatalf("Publishers.Get returned error: %v", err)
+		}
+	}
+
+	if client.SwitchedUser() != 42 {
+		t.Errorf("SwitchedUser is %d after re-login, expected 42", client.SwitchedUser())
+	}
+
+	client.RestoreUser()
+	if _, err := client.Publishers.Get(2); err != nil {
+		t.Fatalf("Publishers.Get returned error: %v", err)
+	}
+
+	expected := []string{"switched-1", "switched-1", "switched-2", "login-2"}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("requests were made with tokens %v, expected %v", tokens, expected)
+	}
+
+	if client.SwitchedUser() != 0 {
+		t.Errorf("SwitchedUser is %d after RestoreUser", client.SwitchedUser())
+	}
+}
@@ -0,0 +1,88 @@
+package appnexus
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// NotFoundError is returned by the GetByCode, UpdateByCode and DeleteByCode
+// methods when no object of the service has the code
+type NotFoundError struct {
+	Service string
+	Code    string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("AppNexus: no %s with code %q", e.Service, e.Code)
+}
+
+// IsNotFound reports whether err is a *NotFoundError
+func IsNotFound(err error) bool {
+	var notFound *NotFoundError
+	return errors.As(err, &notFound)
+}
+
+// codeLookupError turns the outcome of a lookup by code into a
+// *NotFoundError when AppNexus reported no match or returned no object
+func codeLookupError(service, code string, id int64, err error) error {
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorID == "NOTFOUND" {
+			return &NotFoundError{Service: service, Code: code}
+		}
+		return err
+	}
+
+	if id == 0 {
+		return &NotFoundError{Service: service, Code: code}
+	}
+
+	return nil
+}
+
+// getByCode gets the object of scope with the given code, returning a
+// *NotFoundError if none has it
+func getByCode[T any, P object[T]](s *Service[T], scope url.Values, code string) (*T, error) {
+	if code == "" {
+		return nil, fmt.Errorf("GetByCode %s requires a code", s.name())
+	}
+
+	q := url.Values{"code": {code}}
+	for name, values := range scope {
+		q[name] = values
+	}
+
+	var id int64
+	item, err := s.Get(q)
+	if err == nil {
+		id = *P(item).objectID()
+	}
+	if err := codeLookupError(s.key, code, id, err); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// updateByCode updates the object of scope with the given code, looking up
+// its ID
+func updateByCode[T any, P object[T]](s *Service[T], scope url.Values, code string, item T, safe bool) (*Response, error) {
+	current, err := getByCode[T, P](s, scope, code)
+	if err != nil {
+		return nil, err
+	}
+
+	*P(&item).objectID() = *P(current).objectID()
+	return updateObject[T, P](s, scope, item, safe)
+}
+
+// deleteByCode deletes the object of scope with the given code
+func deleteByCode[T any, P object[T]](s *Service[T], scope url.Values, code string) error {
+	current, err := getByCode[T, P](s, scope, code)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteObjects(scope, []int64{*P(current).objectID()})
+}
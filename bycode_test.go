@@ -0,0 +1,87 @@
+package appnexus
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSegmentService_GetByCode(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/segment/1", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("code"); got != "auto suv" {
+			t.Errorf("GetByCode sent code %q", got)
+		}
+		fmt.Fprint(w, `{"response":{"status":"OK","segment":{"id":5,"code":"auto suv","short_name":"SUV"}}}`)
+	})
+
+	segment, err := client.Segments.GetByCode(1, "auto suv")
+	if err != nil {
+		t.Fatalf("GetByCode returned error: %v", err)
+	}
+
+	if segment.ID != 5 || segment.ShortName != "SUV" {
+		t.Errorf("GetByCode returned %+v", segment)
+	}
+}
+
+func TestPublisherService_GetByCodeNotFound(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/publisher", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"error_id":"NOTFOUND","error":"publisher not found"}}`)
+	})
+	mux.HandleFunc("/deal", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"status":"OK","count":0,"deals":[]}}`)
+	})
+
+	if _, err := client.Publishers.GetByCode("missing"); !IsNotFound(err) {
+		t.Errorf("Publishers.GetByCode returned %v, expected a *NotFoundError", err)
+	}
+
+	err := client.Deals.DeleteByCode("missing")
+	if !IsNotFound(err) {
+		t.Fatalf("Deals.DeleteByCode returned %v, expected a *NotFoundError", err)
+	}
+
+	if err.Error() != `AppNexus: no deal with code "missing"` {
+		t.Errorf("NotFoundError reads %q", err.Error())
+	}
+}
+
+func TestByCodeWrites(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	mux.HandleFunc("/site", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		fmt.Fprint(w, `{"response":{"status":"OK","site":{"id":3,"publisher_id":2,"code":"news"}}}`)
+	})
+	mux.HandleFunc("/placement", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		fmt.Fprint(w, `{"response":{"status":"OK","placement":{"id":6,"publisher_id":2,"code":"lb"}}}`)
+	})
+
+	if _, err := client.Sites.UpdateByCode("news", Site{Name: "News"}); err != nil {
+		t.Fatalf("Sites.UpdateByCode returned error: %v", err)
+	}
+
+	if err := client.Placements.DeleteByCode("lb", 2); err != nil {
+		t.Fatalf("Placements.DeleteByCode returned error: %v", err)
+	}
+
+	expected := []string{
+		"GET /site?code=news",
+		"PUT /site?id=3&publisher_id=2",
+		"GET /placement?code=lb&publisher_id=2",
+		"DELETE /placement?id=6&publisher_id=2",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("ByCode methods sent\n%v\nexpected\n%v", calls, expected)
+	}
+}
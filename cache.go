@@ -0,0 +1,108 @@
+package appnexus
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LookupCache stores the raw results of lookup requests by key
+type LookupCache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, data []byte)
+}
+
+// MemoryCache is a LookupCache held in memory, with entries expiring after TTL
+type MemoryCache struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns an in-memory cache whose entries live for ttl
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{TTL: ttl}
+}
+
+// Get returns the data stored under key unless it has expired
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+
+	return e.data, true
+}
+
+// Set stores data under key
+func (c *MemoryCache) Set(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]memoryEntry)
+	}
+
+	c.entries[key] = memoryEntry{data: data, expires: time.Now().Add(c.TTL)}
+}
+
+// DiskCache is a LookupCache kept as one file per key in Dir, so that it
+// survives between runs. Entries expire TTL after the file was written.
+type DiskCache struct {
+	Dir string
+	TTL time.Duration
+}
+
+// NewDiskCache returns a cache storing its entries in dir for ttl
+func NewDiskCache(dir string, ttl time.Duration) *DiskCache {
+	return &DiskCache{Dir: dir, TTL: ttl}
+}
+
+// Get returns the data stored under key unless it has expired or cannot be
+// read
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > c.TTL {
+		return nil, false
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+// Set stores data under key. Write errors are ignored as the cache is only an
+// optimisation.
+func (c *DiskCache) Set(key string, data []byte) {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return
+	}
+
+	path := c.path(key)
+	if err := ioutil.WriteFile(path+".tmp", data, 0644); err != nil {
+		return
+	}
+
+	_ = os.Rename(path+".tmp", path)
+}
+
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.Dir, url.QueryEscape(key)+".json")
+}
@@ -0,0 +1,65 @@
+package appnexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// RawResponse is the response of any service with its fields left
+// undecoded, for services this package does not model
+type RawResponse struct {
+	Obj map[string]json.RawMessage `json:"response"`
+}
+
+// Decode decodes a field of the response, such as "creative" or
+// "creatives", into v
+func (r *RawResponse) Decode(field string, v interface{}) error {
+	data, ok := r.Obj[field]
+	if !ok {
+		return fmt.Errorf("response has no field %q", field)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// Call sends a request to any AppNexus service, such as "creative" or
+// "line-item", with the client's login, rate limiting and retries. body is
+// JSON encoded if not nil and the response is decoded into out like the
+// services do: out may be a *RawResponse, a typed response struct, an
+// io.Writer receiving the raw body, or nil. params are sent as the query
+// string. Cancelling ctx also ends a wait for the rate limit to reset, both
+// before the request and after a 429 response.
+func (c *Client) Call(ctx context.Context, method, service string, params url.Values, body interface{}, out interface{}) (*Response, error) {
+	if service == "" {
+		return nil, errors.New("Call requires a service")
+	}
+
+	path := service
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	return c.send(ctx, method, path, body, out)
+}
+
+// send sends a request for path with ctx attached, so that cancelling ctx
+// ends the request and any wait for the rate limit. A nil ctx is treated as
+// context.Background().
+func (c *Client) send(ctx context.Context, method, path string, body interface{}, v interface{}) (*Response, error) {
+	req, err := c.newRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		req = req.WithContext(ctx)
+	}
+
+	return c.do(req, v)
+}
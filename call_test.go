@@ -0,0 +1,111 @@
+package appnexus
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClient_Call(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/creative", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method != "PUT" || r.URL.RawQuery != "id=9" || string(body) != `{"creative":{"state":"inactive"}}`+"\n" {
+			t.Errorf("Call sent %s %s %s", r.Method, r.URL.RequestURI(), body)
+		}
+		fmt.Fprint(w, `{"response":{"status":"OK","id":9,"creative":{"id":9,"state":"inactive"}}}`)
+	})
+
+	body := map[string]interface{}{"creative": map[string]string{"state": "inactive"}}
+	out := &RawResponse{}
+	resp, err := client.Call(context.Background(), "PUT", "creative", url.Values{"id": {"9"}}, body, out)
+	if err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+
+	if id, _ := resp.Obj.ID.Int64(); id != 9 {
+		t.Errorf("Call returned ID %v", resp.Obj.ID)
+	}
+
+	creative := struct {
+		ID    int64  `json:"id"`
+		State string `json:"state"`
+	}{}
+	if err := out.Decode("creative", &creative); err != nil || creative.ID != 9 || creative.State != "inactive" {
+		t.Errorf("Decode returned %+v, %v", creative, err)
+	}
+
+	if err := out.Decode("creatives", &creative); err == nil {
+		t.Error("Decode of a missing field returned no error")
+	}
+}
+
+func TestClient_CallError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/line-item", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"error_id":"SYNTAX","error":"invalid field"}}`)
+	})
+
+	if _, err := client.Call(context.Background(), "GET", "line-item", nil, nil, &RawResponse{}); err == nil {
+		t.Error("Call returned no error for an API error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Call(ctx, "GET", "line-item", nil, nil, nil); err != context.Canceled {
+		t.Errorf("Call with a cancelled context returned %v", err)
+	}
+}
+
+func TestClient_CallCancelledWhileRateLimited(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/line-item", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.Call(ctx, "GET", "line-item", nil, nil, nil); err != context.DeadlineExceeded {
+		t.Errorf("Call returned %v, expected context.DeadlineExceeded", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Call waited %v for the rate limit after its context expired", elapsed)
+	}
+}
+
+func TestClient_CallCancelledBeforeRateLimitReset(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/line-item", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Call sent a request before the rate limit reset")
+	})
+
+	client.Rate = Rate{Reads: 100, ReadLimit: 100, ReadLimitSeconds: 600}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.Call(ctx, "GET", "line-item", nil, nil, nil); err != context.DeadlineExceeded {
+		t.Errorf("Call returned %v, expected context.DeadlineExceeded", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Call waited %v for the rate limit after its context expired", elapsed)
+	}
+}
@@ -0,0 +1,166 @@
+package appnexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// ChangeLogService handles all requests to the change log service API, which
+// records who changed an object, when, and which fields
+type ChangeLogService struct {
+	client  *Client
+	svc     *Service[ChangeLogEntry]
+	details *Service[ChangeLogDetail]
+}
+
+// ChangeLogEntry is one change to an object. Changes is filled in by History
+// and Changes, as the change log lists entries without their fields.
+type ChangeLogEntry struct {
+	TransactionID string        `json:"transaction_id"`
+	Service       string        `json:"service"`
+	ResourceID    int64         `json:"resource_id"`
+	Method        string        `json:"method"`
+	UserID        int64         `json:"user_id"`
+	UserFullName  string        `json:"user_full_name,omitempty"`
+	CreatedOn     Time          `json:"created_on,omitzero"`
+	Changes       []FieldChange `json:"changes,omitempty"`
+}
+
+// ChangeLogDetail holds the fields changed by one transaction
+type ChangeLogDetail struct {
+	TransactionID string        `json:"transaction_id"`
+	Changes       []FieldChange `json:"changes"`
+}
+
+// FieldChange is the value of a field before and after a change, as raw
+// JSON. Before is null for fields set by the creation of the object.
+type FieldChange struct {
+	Name   string          `json:"name"`
+	Before json.RawMessage `json:"old_value"`
+	After  json.RawMessage `json:"new_value"`
+}
+
+// newChangeLogService returns the service with its change-log and
+// change-log-detail endpoints
+func newChangeLogService(c *Client) *ChangeLogService {
+	return &ChangeLogService{
+		client: c,
+		svc: NewService[ChangeLogEntry](c, "change_log", "change_logs", func(url.Values) string {
+			return "change-log"
+		}),
+		details: NewService[ChangeLogDetail](c, "change_log_detail", "change_log_details", func(url.Values) string {
+			return "change-log-detail"
+		}),
+	}
+}
+
+// changeLogObject selects the change log of an object of a service
+func changeLogObject(service string, id int64) url.Values {
+	return url.Values{"service": {service}, "resource_id": {strconv.FormatInt(id, 10)}}
+}
+
+// List returns one page of the change log of an object of a service, such as
+// "deal", without the changed fields
+func (s *ChangeLogService) List(service string, id int64, opt *ListOptions) ([]ChangeLogEntry, *Response, error) {
+	return s.svc.List(changeLogObject(service, id), opt)
+}
+
+// Detail returns the fields changed by one transaction on an object
+func (s *ChangeLogService) Detail(service string, id int64, transactionID string) ([]FieldChange, error) {
+	return s.detail(context.Background(), service, id, transactionID)
+}
+
+// detail is Detail with ctx attached to the request
+func (s *ChangeLogService) detail(ctx context.Context, service string, id int64, transactionID string) ([]FieldChange, error) {
+	if transactionID == "" {
+		return nil, errors.New("Detail ChangeLog requires a transaction ID")
+	}
+
+	q := changeLogObject(service, id)
+	q.Set("transaction_id", transactionID)
+
+	detail, err := s.details.get(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return detail.Changes, nil
+}
+
+// Changes returns the whole change log of an object of a service, each entry
+// with its changed fields. It makes a request per entry, each with ctx
+// attached as Call does, so cancelling ctx ends the request in flight or the
+// wait for the rate limit. A nil ctx is treated as context.Background().
+func (s *ChangeLogService) Changes(ctx context.Context, service string, id int64) ([]ChangeLogEntry, error) {
+	if id < 1 {
+		return nil, errors.New("Changes requires an object ID")
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	entries, err := s.svc.listAll(ctx, changeLogObject(service, id))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		changes, err := s.detail(ctx, service, id, entries[i].TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		entries[i].Changes = changes
+	}
+
+	return entries, nil
+}
+
+// History returns the change log of a Deal, Placement, Site, Publisher or
+// Segment, or a pointer to one, each entry with its changed fields. ctx is
+// used as by Changes.
+func (s *ChangeLogService) History(ctx context.Context, obj interface{}) ([]ChangeLogEntry, error) {
+	var service string
+	var o interface{ objectID() *int64 }
+	isNil := false
+
+	switch v := obj.(type) {
+	case Deal:
+		service, o = "deal", &v
+	case *Deal:
+		service, o, isNil = "deal", v, v == nil
+	case Placement:
+		service, o = "placement", &v
+	case *Placement:
+		service, o, isNil = "placement", v, v == nil
+	case Site:
+		service, o = "site", &v
+	case *Site:
+		service, o, isNil = "site", v, v == nil
+	case Publisher:
+		service, o = "publisher", &v
+	case *Publisher:
+		service, o, isNil = "publisher", v, v == nil
+	case Segment:
+		service, o = "segment", &v
+	case *Segment:
+		service, o, isNil = "segment", v, v == nil
+	default:
+		return nil, fmt.Errorf("History does not support %T", obj)
+	}
+
+	if isNil {
+		return nil, fmt.Errorf("History requires a %s, not nil", service)
+	}
+
+	id := *o.objectID()
+	if id < 1 {
+		return nil, fmt.Errorf("History requires a %s to have an ID already", service)
+	}
+
+	return s.Changes(ctx, service, id)
+}
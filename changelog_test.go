@@ -0,0 +1,124 @@
+package appnexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChangeLogService_History(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	mux.HandleFunc("/change-log", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.RequestURI())
+		fmt.Fprint(w, `{"response":{"status":"OK","count":1,"change_logs":[
+			{"transaction_id":"tx1","service":"deal","resource_id":42,"method":"put","user_id":5,"user_full_name":"Jo Smith","created_on":"2016-05-04 10:20:30"}
+		]}}`)
+	})
+	mux.HandleFunc("/change-log-detail", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.RequestURI())
+		fmt.Fprint(w, `{"response":{"status":"OK","change_log_detail":{"transaction_id":"tx1","changes":[
+			{"name":"active","old_value":true,"new_value":false}
+		]}}}`)
+	})
+
+	entries, err := client.ChangeLogs.History(context.Background(), &Deal{ID: 42})
+	if err != nil {
+		t.Fatalf("History returned error: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("History returned %d entries, expected 1", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.UserID != 5 || entry.UserFullName != "Jo Smith" || entry.ResourceID != 42 || entry.CreatedOn.IsZero() {
+		t.Errorf("History returned entry %+v", entry)
+	}
+
+	changes := []FieldChange{{Name: "active", Before: json.RawMessage("true"), After: json.RawMessage("false")}}
+	if !reflect.DeepEqual(entry.Changes, changes) {
+		t.Errorf("History returned changes %+v, expected %+v", entry.Changes, changes)
+	}
+
+	expected := []string{
+		"/change-log?num_elements=100&resource_id=42&service=deal",
+		"/change-log-detail?resource_id=42&service=deal&transaction_id=tx1",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("History requested %v, expected %v", calls, expected)
+	}
+
+	if _, err := client.ChangeLogs.Changes(nil, "deal", 42); err != nil {
+		t.Errorf("Changes with a nil context returned error: %v", err)
+	}
+
+	if _, err := client.ChangeLogs.History(context.Background(), Site{}); err == nil {
+		t.Errorf("History accepted a site without an ID")
+	}
+	if _, err := client.ChangeLogs.History(context.Background(), (*Placement)(nil)); err == nil {
+		t.Errorf("History accepted a nil placement")
+	}
+	if _, err := client.ChangeLogs.History(context.Background(), Member{ID: 1}); err == nil {
+		t.Errorf("History accepted a member")
+	}
+}
+
+func TestChangeLogService_ChangesCancel(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/change-log", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"status":"OK","count":3,"change_logs":[
+			{"transaction_id":"tx1"},{"transaction_id":"tx2"},{"transaction_id":"tx3"}
+		]}}`)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	details := 0
+	mux.HandleFunc("/change-log-detail", func(w http.ResponseWriter, r *http.Request) {
+		details++
+		cancel()
+		fmt.Fprint(w, `{"response":{"status":"OK","change_log_detail":{"changes":[]}}}`)
+	})
+
+	if _, err := client.ChangeLogs.Changes(ctx, "deal", 42); !errors.Is(err, context.Canceled) {
+		t.Errorf("Changes returned %v, expected context.Canceled", err)
+	}
+	if details != 1 {
+		t.Errorf("Changes requested %d details after its context was cancelled", details)
+	}
+}
+
+func TestChangeLogService_ChangesCancelInFlight(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/change-log", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"status":"OK","count":1,"change_logs":[{"transaction_id":"tx1"}]}}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	mux.HandleFunc("/change-log-detail", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	start := time.Now()
+	if _, err := client.ChangeLogs.Changes(ctx, "deal", 42); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Changes returned %v, expected context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Changes took %v, expected the request in flight to end with its context", elapsed)
+	}
+}
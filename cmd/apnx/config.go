@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// defaultEndPoint is used when neither the environment nor the config file
+// name an API endpoint
+const defaultEndPoint = "https://api.appnexus.com/"
+
+// config holds the credentials and defaults used to connect to the API
+type config struct {
+	EndPoint string `json:"endpoint"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	MemberID int    `json:"member_id"`
+}
+
+// defaultConfigPath is ~/.apnx.json
+func defaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".apnx.json"
+	}
+
+	return filepath.Join(home, ".apnx.json")
+}
+
+// loadConfig reads the config file, if present, and then applies the
+// APPNEXUS_ENDPOINT, APPNEXUS_USERNAME and APPNEXUS_PASSWORD environment
+// variables on top of it
+func loadConfig(path string) (*config, error) {
+	cfg := &config{}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, errors.New("config " + path + ": " + err.Error())
+		}
+	}
+
+	if v := os.Getenv("APPNEXUS_ENDPOINT"); v != "" {
+		cfg.EndPoint = v
+	}
+	if v := os.Getenv("APPNEXUS_USERNAME"); v != "" {
+		cfg.Username = v
+	}
+	if v := os.Getenv("APPNEXUS_PASSWORD"); v != "" {
+		cfg.Password = v
+	}
+
+	if cfg.EndPoint == "" {
+		cfg.EndPoint = defaultEndPoint
+	}
+
+	if cfg.Username == "" || cfg.Password == "" {
+		return nil, errors.New("no credentials: set APPNEXUS_USERNAME and APPNEXUS_PASSWORD or add them to " + path)
+	}
+
+	return cfg, nil
+}
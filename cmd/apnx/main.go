@@ -0,0 +1,518 @@
+// Command apnx performs everyday AppNexus operations from the command line.
+//
+// Usage:
+//
+//	apnx [global flags] <service> <action> [flags] [id]
+//
+// Services and actions:
+//
+//	segment   list|get|create|update|delete
+//	deal      list|get|create|update|delete
+//	site      list|get|create|update|delete
+//	placement list|get|create|update|delete
+//	publisher list|get|create|update|delete
+//	member    show
+//
+// Credentials are read from ~/.apnx.json and the APPNEXUS_ENDPOINT,
+// APPNEXUS_USERNAME and APPNEXUS_PASSWORD environment variables. Objects for
+// create and update are given as JSON with -data or -file; update merges the
+// given fields into the current object before sending it.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/tnako/appnexus"
+)
+
+type app struct {
+	client *appnexus.Client
+	out    io.Writer
+	format string
+	member int
+}
+
+type handler func(a *app, action string, args []string) error
+
+var services = map[string]handler{
+	"segment":   segmentCommand,
+	"deal":      dealCommand,
+	"site":      siteCommand,
+	"placement": placementCommand,
+	"publisher": publisherCommand,
+	"member":    memberCommand,
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "apnx:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("apnx", flag.ContinueOnError)
+	configPath := fs.String("config", defaultConfigPath(), "path to the JSON config file")
+	format := fs.String("output", "table", "output format: json, table or csv")
+	member := fs.Int("member", 0, "member ID, defaults to the member of the login")
+	dryRun := fs.Bool("dry-run", false, "print write requests instead of sending them")
+	validate := fs.Bool("validate", false, "check writes against the service meta before sending them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: apnx [flags] <segment|deal|site|placement|publisher|member> <action> [flags] [id]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return errors.New("missing service or action")
+	}
+
+	cmd, ok := services[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown service %q", fs.Arg(0))
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	c, err := appnexus.NewClient(cfg.EndPoint)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Login(cfg.Username, cfg.Password); err != nil {
+		return err
+	}
+	c.DryRun = *dryRun
+	c.Validate = *validate
+
+	a := &app{client: c, out: os.Stdout, format: *format, member: *member}
+	if a.member == 0 {
+		a.member = cfg.MemberID
+	}
+
+	// An explicit member scopes every request, for network logins
+	if a.member > 0 {
+		c = c.ForMember(a.member)
+		a.client = c
+	}
+
+	if err := cmd(a, fs.Arg(1), fs.Args()[2:]); err != nil {
+		return err
+	}
+
+	for _, p := range c.Planned() {
+		fmt.Fprintf(os.Stderr, "DRY RUN %s %s\n", p.Method, p.URL)
+		if len(p.Body) > 0 {
+			fmt.Fprintf(os.Stderr, "%s\n", p.Body)
+		}
+	}
+
+	return nil
+}
+
+// memberID returns the member from the flags or config, falling back to the
+// default member of the login
+func (a *app) memberID() (int, error) {
+	if a.member > 0 {
+		return a.member, nil
+	}
+
+	m, err := a.client.Members.GetDefault()
+	if err != nil {
+		return 0, err
+	}
+
+	a.member = m.ID
+	return a.member, nil
+}
+
+// actionFlags are the flags shared by every action
+type actionFlags struct {
+	*flag.FlagSet
+	data      *string
+	file      *string
+	publisher *int64
+	start     *int
+	num       *int
+}
+
+func newActionFlags(name string) *actionFlags {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	return &actionFlags{
+		FlagSet:   fs,
+		data:      fs.String("data", "", "object as JSON"),
+		file:      fs.String("file", "", "read the object as JSON from a file, - for stdin"),
+		publisher: fs.Int64("publisher", 0, "publisher ID"),
+		start:     fs.Int("start", 0, "start element for list"),
+		num:       fs.Int("num", 100, "number of elements for list"),
+	}
+}
+
+// id parses the single positional ID argument
+func (f *actionFlags) id() (int64, error) {
+	if f.NArg() != 1 {
+		return 0, errors.New("expected a single ID argument")
+	}
+
+	return strconv.ParseInt(f.Arg(0), 10, 64)
+}
+
+// decode unmarshals the -data or -file JSON on top of v
+func (f *actionFlags) decode(v interface{}) error {
+	var data []byte
+	var err error
+
+	switch {
+	case *f.data != "":
+		data = []byte(*f.data)
+	case *f.file == "-":
+		data, err = ioutil.ReadAll(os.Stdin)
+	case *f.file != "":
+		data, err = ioutil.ReadFile(*f.file)
+	default:
+		return errors.New("expected the object in -data or -file")
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// created prints the ID assigned to a new object
+func (a *app) created(id int64) error {
+	return render(a.out, a.format, struct {
+		ID int64 `json:"id"`
+	}{id})
+}
+
+func segmentCommand(a *app, action string, args []string) error {
+	f := newActionFlags("segment " + action)
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+
+	memberID, err := a.memberID()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		segments, _, err := a.client.Segments.List(memberID, &appnexus.ListOptions{StartElement: *f.start, NumElements: *f.num})
+		if err != nil {
+			return err
+		}
+		return render(a.out, a.format, segments)
+	case "get":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		segment, err := a.client.Segments.Get(memberID, int(id))
+		if err != nil {
+			return err
+		}
+		return render(a.out, a.format, segment)
+	case "create":
+		segment := &appnexus.Segment{MemberID: memberID}
+		if err := f.decode(segment); err != nil {
+			return err
+		}
+		if _, err := a.client.Segments.Add(memberID, segment); err != nil {
+			return err
+		}
+		return a.created(segment.ID)
+	case "update":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		segment, err := a.client.Segments.Get(memberID, int(id))
+		if err != nil {
+			return err
+		}
+		if err := f.decode(segment); err != nil {
+			return err
+		}
+		segment.ID = id
+		_, err = a.client.Segments.Update(memberID, *segment)
+		return err
+	case "delete":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		return a.client.Segments.Delete(memberID, id)
+	}
+
+	return fmt.Errorf("unknown segment action %q", action)
+}
+
+func dealCommand(a *app, action string, args []string) error {
+	f := newActionFlags("deal " + action)
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		deals, _, err := a.client.Deals.List()
+		if err != nil {
+			return err
+		}
+		return render(a.out, a.format, deals)
+	case "get":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		deal, err := a.client.Deals.Get(id)
+		if err != nil {
+			return err
+		}
+		return render(a.out, a.format, deal)
+	case "create":
+		deal := &appnexus.Deal{}
+		if err := f.decode(deal); err != nil {
+			return err
+		}
+		if _, err := a.client.Deals.Add(deal); err != nil {
+			return err
+		}
+		return a.created(deal.ID)
+	case "update":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		deal, err := a.client.Deals.Get(id)
+		if err != nil {
+			return err
+		}
+		if err := f.decode(deal); err != nil {
+			return err
+		}
+		deal.ID = id
+		_, err = a.client.Deals.Update(*deal)
+		return err
+	case "delete":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		return a.client.Deals.Delete(id)
+	}
+
+	return fmt.Errorf("unknown deal action %q", action)
+}
+
+func siteCommand(a *app, action string, args []string) error {
+	f := newActionFlags("site " + action)
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		sites, _, err := a.client.Sites.List()
+		if err != nil {
+			return err
+		}
+		return render(a.out, a.format, sites)
+	case "get":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		site, err := a.client.Sites.Get(id)
+		if err != nil {
+			return err
+		}
+		return render(a.out, a.format, site)
+	case "create":
+		site := &appnexus.Site{PublisherID: *f.publisher}
+		if err := f.decode(site); err != nil {
+			return err
+		}
+		if _, err := a.client.Sites.Add(site); err != nil {
+			return err
+		}
+		return a.created(site.ID)
+	case "update":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		site, err := a.client.Sites.Get(id)
+		if err != nil {
+			return err
+		}
+		if err := f.decode(site); err != nil {
+			return err
+		}
+		site.ID = id
+		_, err = a.client.Sites.Update(*site)
+		return err
+	case "delete":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		if *f.publisher == 0 {
+			return errors.New("site delete requires -publisher")
+		}
+		return a.client.Sites.Delete(id, *f.publisher)
+	}
+
+	return fmt.Errorf("unknown site action %q", action)
+}
+
+func placementCommand(a *app, action string, args []string) error {
+	f := newActionFlags("placement " + action)
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		if *f.publisher == 0 {
+			return errors.New("placement list requires -publisher")
+		}
+		placements, _, err := a.client.Placements.List(*f.publisher)
+		if err != nil {
+			return err
+		}
+		return render(a.out, a.format, placements)
+	case "get":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		placement, err := a.client.Placements.Get(id)
+		if err != nil {
+			return err
+		}
+		return render(a.out, a.format, placement)
+	case "create":
+		placement := &appnexus.Placement{PublisherID: *f.publisher}
+		if err := f.decode(placement); err != nil {
+			return err
+		}
+		if _, err := a.client.Placements.Add(placement); err != nil {
+			return err
+		}
+		return a.created(placement.ID)
+	case "update":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		placement, err := a.client.Placements.Get(id)
+		if err != nil {
+			return err
+		}
+		if err := f.decode(placement); err != nil {
+			return err
+		}
+		placement.ID = id
+		_, err = a.client.Placements.Update(*placement)
+		return err
+	case "delete":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		if *f.publisher == 0 {
+			return errors.New("placement delete requires -publisher")
+		}
+		return a.client.Placements.Delete(id, *f.publisher)
+	}
+
+	return fmt.Errorf("unknown placement action %q", action)
+}
+
+func publisherCommand(a *app, action string, args []string) error {
+	f := newActionFlags("publisher " + action)
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		publishers, _, err := a.client.Publishers.List()
+		if err != nil {
+			return err
+		}
+		return render(a.out, a.format, publishers)
+	case "get":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		publisher, err := a.client.Publishers.Get(id)
+		if err != nil {
+			return err
+		}
+		return render(a.out, a.format, publisher)
+	case "create":
+		publisher := &appnexus.Publisher{}
+		if err := f.decode(publisher); err != nil {
+			return err
+		}
+		if _, err := a.client.Publishers.Add(publisher); err != nil {
+			return err
+		}
+		return a.created(publisher.ID)
+	case "update":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		publisher, err := a.client.Publishers.Get(id)
+		if err != nil {
+			return err
+		}
+		if err := f.decode(publisher); err != nil {
+			return err
+		}
+		publisher.ID = id
+		_, err = a.client.Publishers.Update(*publisher)
+		return err
+	case "delete":
+		id, err := f.id()
+		if err != nil {
+			return err
+		}
+		return a.client.Publishers.Delete(id)
+	}
+
+	return fmt.Errorf("unknown publisher action %q", action)
+}
+
+func memberCommand(a *app, action string, args []string) error {
+	if action != "show" {
+		return fmt.Errorf("unknown member action %q", action)
+	}
+
+	member, err := a.client.Members.Get(a.member)
+	if err != nil {
+		return err
+	}
+
+	return render(a.out, a.format, member)
+}
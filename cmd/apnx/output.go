@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// render writes v, a struct, pointer to struct or slice of structs, in the
+// requested format: json, table or csv
+func render(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		if err := writeRows(v, func(row []string) error {
+			_, err := fmt.Fprintln(tw, strings.Join(row, "\t"))
+			return err
+		}); err != nil {
+			return err
+		}
+		return tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := writeRows(v, cw.Write); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// writeRows flattens v into a header row followed by one row per struct,
+// using the json names of every scalar or fmt.Stringer field as columns
+func writeRows(v interface{}, write func([]string) error) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	rows := []reflect.Value{rv}
+	if rv.Kind() == reflect.Slice {
+		rows = rows[:0]
+		for i := 0; i < rv.Len(); i++ {
+			rows = append(rows, reflect.Indirect(rv.Index(i)))
+		}
+	}
+
+	var elem reflect.Type
+	if rv.Kind() == reflect.Slice {
+		elem = rv.Type().Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+	} else {
+		elem = rv.Type()
+	}
+
+	if elem.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot render %s as rows", elem)
+	}
+
+	var fields []int
+	var header []string
+	for i := 0; i < elem.NumField(); i++ {
+		f := elem.Field(i)
+		if f.PkgPath != "" || !(scalar(f.Type.Kind()) || f.Type.Implements(stringer)) {
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = f.Name
+		}
+
+		fields = append(fields, i)
+		header = append(header, name)
+	}
+
+	if err := write(header); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		cells := make([]string, len(fields))
+		for j, i := range fields {
+			cells[j] = fmt.Sprint(row.Field(i).Interface())
+		}
+
+		if err := write(cells); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var stringer = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+func scalar(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tnako/appnexus"
+)
+
+func TestRender(t *testing.T) {
+	sites := []appnexus.Site{
+		{ID: 1, PublisherID: 2, Name: "Site one", URL: "http://one.example.com"},
+		{ID: 3, PublisherID: 2, Name: "Site, two"},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := render(buf, "csv", sites); err != nil {
+		t.Fatalf("render returned error: %v", err)
+	}
+
+	expected := "id,publisher_id,code,state,name,url,supply_type,last_modified\n" +
+		"1,2,,,Site one,http://one.example.com,,\n" +
+		"3,2,,,\"Site, two\",,,\n"
+	if actual := buf.String(); actual != expected {
+		t.Errorf("render csv wrote\n%s\nexpected\n%s", actual, expected)
+	}
+
+	buf.Reset()
+	if err := render(buf, "table", &sites[0]); err != nil {
+		t.Fatalf("render returned error: %v", err)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasPrefix(lines[0], "id  publisher_id  code") || !strings.Contains(lines[1], "Site one") {
+		t.Errorf("render table wrote\n%s", buf.String())
+	}
+
+	if err := render(buf, "xml", sites); err == nil {
+		t.Errorf("render accepted an unknown format")
+	}
+}
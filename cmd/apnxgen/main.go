@@ -0,0 +1,316 @@
+// Command apnxgen generates Go models from the meta output of AppNexus
+// services.
+//
+// Usage:
+//
+//	apnxgen -meta dir -out dir [-package name]
+//
+// Every file in the meta directory, such as segment.json, holds the saved
+// response of the service's meta call (GET /segment/meta). For each one a
+// file such as segment_gen.go is written with the service's struct, a struct
+// for each nested object and a string type with constants for each enum.
+// Read-only and required fields are marked in their doc comments and with an
+// appnexus struct tag.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tnako/appnexus"
+)
+
+func main() {
+	metaDir := flag.String("meta", "meta", "directory of meta JSON files")
+	outDir := flag.String("out", ".", "directory to write the models to")
+	pkg := flag.String("package", "models", "package name of the models")
+	flag.Parse()
+
+	if err := run(*metaDir, *outDir, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "apnxgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(metaDir, outDir, pkg string) error {
+	files, err := filepath.Glob(filepath.Join(metaDir, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	if len(files) == 0 {
+		return fmt.Errorf("no meta files in %s", metaDir)
+	}
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		meta, err := appnexus.ParseMeta(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %s", file, err.Error())
+		}
+
+		service := strings.TrimSuffix(filepath.Base(file), ".json")
+		source := filepath.ToSlash(filepath.Join(filepath.Base(metaDir), filepath.Base(file)))
+		src, err := generate(pkg, service, source, meta)
+		if err != nil {
+			return fmt.Errorf("%s: %s", file, err.Error())
+		}
+
+		out := filepath.Join(outDir, strings.Replace(service, "-", "_", -1)+"_gen.go")
+		if err := ioutil.WriteFile(out, src, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generator collects the declarations of one service's file
+type generator struct {
+	structs bytes.Buffer
+	enums   bytes.Buffer
+	imports map[string]bool
+}
+
+// generate returns the formatted source of the models of a service
+func generate(pkg, service, source string, meta *appnexus.ServiceMeta) ([]byte, error) {
+	g := &generator{imports: make(map[string]bool)}
+
+	name := goName(service)
+	if err := g.writeStruct(name, fmt.Sprintf("%s is an object of the %s service.", name, service), meta.Fields); err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "// Code generated by apnxgen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(buf, "package %s\n\n", pkg)
+
+	if len(g.imports) > 0 {
+		var imports []string
+		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+
+		buf.WriteString("import (\n")
+		for _, imp := range imports {
+			fmt.Fprintf(buf, "\t%q\n", imp)
+		}
+		buf.WriteString(")\n\n")
+	}
+
+	buf.Write(g.structs.Bytes())
+	buf.Write(g.enums.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+// writeStruct declares a struct of fields, declaring the types of nested
+// objects and enums after it
+func (g *generator) writeStruct(name, doc string, fields []appnexus.MetaField) error {
+	type nested struct {
+		name   string
+		doc    string
+		fields []appnexus.MetaField
+	}
+	var later []nested
+
+	body := new(bytes.Buffer)
+	for _, field := range fields {
+		fieldName := goName(field.Name)
+		typeName := name + fieldName
+
+		var goType string
+		switch field.Type {
+		case appnexus.MetaInt:
+			goType = "int"
+			if field.Name == "id" || strings.HasSuffix(field.Name, "_id") {
+				goType = "int64"
+			}
+		case appnexus.MetaDouble:
+			goType = "float64"
+		case appnexus.MetaMoney:
+			goType = "appnexus.Decimal"
+			g.imports["github.com/tnako/appnexus"] = true
+		case appnexus.MetaString:
+			goType = "string"
+		case appnexus.MetaBoolean:
+			goType = "bool"
+		case appnexus.MetaDate, appnexus.MetaTimestamp:
+			goType = "appnexus.Time"
+			g.imports["github.com/tnako/appnexus"] = true
+		case appnexus.MetaEnum:
+			goType = typeName
+			g.writeEnum(typeName, field)
+		case appnexus.MetaObject:
+			goType = "*" + typeName
+			later = append(later, nested{typeName, fmt.Sprintf("%s is the %s of a %s.", typeName, field.Name, name), field.Fields})
+		case appnexus.MetaArrayOfObjects:
+			typeName = singular(typeName)
+			goType = "[]" + typeName
+			later = append(later, nested{typeName, fmt.Sprintf("%s is one of the %s of a %s.", typeName, field.Name, name), field.Fields})
+		case appnexus.MetaArrayOfStrings:
+			goType = "[]string"
+		case appnexus.MetaArrayOfInts:
+			goType = "[]int64"
+		default:
+			return fmt.Errorf("field %s has unknown type %q", field.Name, field.Type)
+		}
+
+		if comment := fieldComment(field); comment != "" {
+			for _, line := range wrap(comment, 74) {
+				fmt.Fprintf(body, "\t// %s\n", line)
+			}
+		}
+		fmt.Fprintf(body, "\t%s %s `%s`\n", fieldName, goType, fieldTag(field))
+	}
+
+	for _, line := range wrap(doc, 77) {
+		fmt.Fprintf(&g.structs, "// %s\n", line)
+	}
+	fmt.Fprintf(&g.structs, "type %s struct {\n%s}\n\n", name, body.String())
+
+	for _, n := range later {
+		if err := g.writeStruct(n.name, n.doc, n.fields); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeEnum declares a string type with a constant for each value
+func (g *generator) writeEnum(name string, field appnexus.MetaField) {
+	fmt.Fprintf(&g.enums, "// %s is a value of the %s field.\n", name, field.Name)
+	fmt.Fprintf(&g.enums, "type %s string\n\n", name)
+
+	if len(field.EnumValues) == 0 {
+		return
+	}
+
+	fmt.Fprintf(&g.enums, "// Values of %s\n", name)
+	g.enums.WriteString("const (\n")
+	for _, value := range field.EnumValues {
+		fmt.Fprintf(&g.enums, "\t%s%s %s = %q\n", name, goName(value), name, value)
+	}
+	g.enums.WriteString(")\n\n")
+}
+
+// fieldComment is the description of a field with its markers
+func fieldComment(field appnexus.MetaField) string {
+	comment := field.Description
+	if field.ReadOnly {
+		comment = strings.TrimSpace(comment + " Read-only.")
+	}
+	if field.RequiredOn != "" {
+		comment = strings.TrimSpace(comment + " Required on " + field.RequiredOn + ".")
+	}
+
+	return comment
+}
+
+// fieldTag returns the struct tag of a field. Booleans and required fields
+// are always sent; money and times are left out when zero.
+func fieldTag(field appnexus.MetaField) string {
+	opt := ",omitempty"
+	switch {
+	case field.Type == appnexus.MetaBoolean || field.RequiredOn != "":
+		opt = ""
+	case field.Type == appnexus.MetaMoney || field.Type == appnexus.MetaDate || field.Type == appnexus.MetaTimestamp:
+		opt = ",omitzero"
+	}
+
+	tag := fmt.Sprintf(`json:"%s%s"`, field.Name, opt)
+
+	var markers []string
+	if field.ReadOnly {
+		markers = append(markers, "readonly")
+	}
+	if field.RequiredOn != "" {
+		markers = append(markers, "required")
+	}
+	if len(markers) > 0 {
+		tag += fmt.Sprintf(` appnexus:"%s"`, strings.Join(markers, ","))
+	}
+
+	return tag
+}
+
+// initialisms are written in upper case in Go names
+var initialisms = map[string]bool{
+	"api": true, "cpm": true, "dma": true, "id": true, "ip": true,
+	"json": true, "oo": true, "rm": true, "rtb": true, "url": true,
+}
+
+// goName turns a name such as publisher_id or mobile-web into PublisherID or
+// MobileWeb
+func goName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == ' ' || r == '.'
+	})
+
+	var b strings.Builder
+	for _, w := range words {
+		if initialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+
+	if b.Len() == 0 || (b.String()[0] >= '0' && b.String()[0] <= '9') {
+		return "V" + b.String()
+	}
+
+	return b.String()
+}
+
+// singular turns the name of a list into the name of one element
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ses"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s"):
+		return strings.TrimSuffix(name, "s")
+	}
+
+	return name
+}
+
+// wrap splits text into lines of at most width characters
+func wrap(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
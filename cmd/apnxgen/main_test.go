@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tnako/appnexus"
+)
+
+func TestGenerate(t *testing.T) {
+	meta, err := appnexus.ParseMeta(strings.NewReader(`{"response":{"fields":[
+		{"name":"id","type":"int","read_only":true,"description":"The ID of the widget."},
+		{"name":"name","type":"string","required_on":"POST"},
+		{"name":"active","type":"boolean"},
+		{"name":"state","type":"enum","enum_values":["active","inactive"]},
+		{"name":"last_modified","type":"timestamp","read_only":true},
+		{"name":"sizes","type":"array of objects","fields":[{"name":"width","type":"int"}]}
+	]}}`))
+	if err != nil {
+		t.Fatalf("ParseMeta returned error: %v", err)
+	}
+
+	src, err := generate("models", "widget", "meta/widget.json", meta)
+	if err != nil {
+		t.Fatalf("generate returned error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"// Code generated by apnxgen from meta/widget.json. DO NOT EDIT.",
+		"\t// The ID of the widget. Read-only.\n\tID int64 `json:\"id,omitempty\" appnexus:\"readonly\"`",
+		"\t// Required on POST.\n\tName string `json:\"name\" appnexus:\"required\"`",
+		"Active bool `json:\"active\"`",
+		"State WidgetState `json:\"state,omitempty\"`",
+		"LastModified appnexus.Time `json:\"last_modified,omitzero\" appnexus:\"readonly\"`",
+		"Sizes []WidgetSize `json:\"sizes,omitempty\"`",
+		"type WidgetSize struct {\n\tWidth int `json:\"width,omitempty\"`\n}",
+		"WidgetStateInactive WidgetState = \"inactive\"",
+	} {
+		// gofmt aligns fields, so compare with spaces collapsed
+		if !strings.Contains(collapse(string(src)), collapse(expected)) {
+			t.Errorf("generate output lacks %q:\n%s", expected, src)
+		}
+	}
+}
+
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func TestGoName(t *testing.T) {
+	names := map[string]string{
+		"publisher_id":        "PublisherID",
+		"enable_rm_piggyback": "EnableRMPiggyback",
+		"mobile-web":          "MobileWeb",
+		"300x250":             "V300x250",
+	}
+	for name, expected := range names {
+		if actual := goName(name); actual != expected {
+			t.Errorf("goName(%q) = %q, expected %q", name, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package appnexus
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// defaultMergeAttempts is used by the UpdateWithMerge methods when attempts
+// is not positive
+const defaultMergeAttempts = 3
+
+// ConflictError is returned by safe updates when the object was modified
+// after the caller read it. Read is the caller's version and Current the
+// version now stored by AppNexus, both pointers to the service's type.
+type ConflictError struct {
+	Service string
+	ID      int64
+	Read    interface{}
+	Current interface{}
+
+	ReadModified    Time
+	CurrentModified Time
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("AppNexus: %s %d was modified at %s, after the version read at %s", e.Service, e.ID, e.CurrentModified, e.ReadModified)
+}
+
+// IsConflict reports whether err is a *ConflictError
+func IsConflict(err error) bool {
+	var conflict *ConflictError
+	return errors.As(err, &conflict)
+}
+
+// checkVersion returns a *ConflictError when the live last_modified differs
+// from the one the caller read
+func checkVersion(service string, id int64, readModified, currentModified Time, read, current interface{}) error {
+	if readModified.IsZero() {
+		return fmt.Errorf("safe update of %s %d requires LastModified from the version read", service, id)
+	}
+
+	if !readModified.Equal(currentModified) {
+		return &ConflictError{
+			Service:         service,
+			ID:              id,
+			Read:            read,
+			Current:         current,
+			ReadModified:    readModified,
+			CurrentModified: currentModified,
+		}
+	}
+
+	return nil
+}
+
+// updateObject replaces the object of scope with item's ID by item. When safe
+// it re-fetches the object first and fails with a *ConflictError if it was
+// modified since item was read.
+func updateObject[T any, P object[T]](s *Service[T], scope url.Values, item T, safe bool) (*Response, error) {
+	id := *P(&item).objectID()
+	if id < 1 {
+		return nil, fmt.Errorf("Update %s requires an object with an ID already", s.name())
+	}
+
+	params := withID(scope, id)
+	if safe {
+		current, err := s.Get(params)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkVersion(s.key, id, *P(&item).objectVersion(), *P(current).objectVersion(), &item, current); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.Update(params, item)
+}
+
+// updateWithMerge safely updates item, and on conflict calls merge with the
+// current version to reapply the caller's changes before retrying, up to
+// attempts times
+func updateWithMerge[T any, P object[T]](s *Service[T], scope url.Values, item T, merge func(current T) (T, error), attempts int) (*Response, error) {
+	if attempts < 1 {
+		attempts = defaultMergeAttempts
+	}
+
+	for i := 1; ; i++ {
+		resp, err := updateObject[T, P](s, scope, item, true)
+
+		conflict, ok := err.(*ConflictError)
+		if !ok || i >= attempts {
+			return resp, err
+		}
+
+		current := conflict.Current.(*T)
+		item, err = merge(*current)
+		if err != nil {
+			return nil, err
+		}
+
+		*P(&item).objectID() = *P(current).objectID()
+		*P(&item).objectVersion() = *P(current).objectVersion()
+	}
+}
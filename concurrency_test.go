@@ -0,0 +1,72 @@
+package appnexus
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSafeUpdateConflict(t *testing.T) {
+	setup()
+	defer teardown()
+
+	puts := 0
+	mux.HandleFunc("/deal", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			puts++
+			fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"response":{"status":"OK","deal":{"id":7,"name":"theirs","last_modified":"2018-01-02 10:00:00"}}}`)
+	})
+
+	client.SafeUpdates = true
+
+	_, err := client.Deals.Update(Deal{ID: 7, Name: "mine", LastModified: MustParseTime("2018-01-01 09:00:00")})
+	conflict, ok := err.(*ConflictError)
+	if !ok {
+		t.Fatalf("Deals.Update returned %v, expected a *ConflictError", err)
+	}
+
+	if read, current := conflict.Read.(*Deal), conflict.Current.(*Deal); read.Name != "mine" || current.Name != "theirs" {
+		t.Errorf("ConflictError holds %+v and %+v", read, current)
+	}
+
+	if !IsConflict(err) || puts != 0 {
+		t.Errorf("Deals.Update sent %d PUTs on conflict", puts)
+	}
+
+	if _, err := client.Deals.Update(Deal{ID: 7, Name: "mine", LastModified: MustParseTime("2018-01-02 10:00:00")}); err != nil || puts != 1 {
+		t.Errorf("Deals.Update returned %v after %d PUTs for a current version", err, puts)
+	}
+}
+
+func TestUpdateWithMerge(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var sent string
+	mux.HandleFunc("/placement", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			sent = r.URL.RequestURI()
+			fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"response":{"status":"OK","placement":{"id":6,"publisher_id":2,"name":"theirs","code":"c1","last_modified":"2018-01-02 10:00:00"}}}`)
+	})
+
+	merged := 0
+	item := Placement{ID: 6, PublisherID: 2, Name: "mine", LastModified: MustParseTime("2018-01-01 09:00:00")}
+	_, err := client.Placements.UpdateWithMerge(item, func(current Placement) (Placement, error) {
+		merged++
+		current.Name = "mine"
+		return current, nil
+	}, 2)
+	if err != nil {
+		t.Fatalf("Placements.UpdateWithMerge returned error: %v", err)
+	}
+
+	if merged != 1 || sent != "/placement?id=6&publisher_id=2" {
+		t.Errorf("merged %d times and sent %q", merged, sent)
+	}
+}
@@ -2,14 +2,14 @@ package appnexus
 
 import (
 	"errors"
-	"fmt"
-	"net/http"
+	"iter"
 )
 
 // DealService handles all requests to the deal service API
 type DealService struct {
 	*Response
 	client *Client
+	svc    *Service[Deal]
 }
 
 // Type is a nested part of deal within the AppNexus console
@@ -29,120 +29,123 @@ type Buyer struct {
 
 // Deal is an audience deal within the AppNexus console
 type Deal struct {
-	ID          int64        `json:"id,omitempty"`
-	FloorPrice  float64      `json:"floor_price,omitempty"`
-	Code        string       `json:"code"`
-	Name        string       `json:"name"`
-	Active      bool         `json:"active"`
-	StartDate   string       `json:"start_date,omitempty"`
-	EndDate     string       `json:"end_date,omitempty"`
+	ID           int64        `json:"id,omitempty"`
+	FloorPrice   Decimal      `json:"floor_price,omitzero"`
+	Currency     string       `json:"currency,omitempty"`
+	Code         string       `json:"code"`
+	Name         string       `json:"name"`
+	Active       bool         `json:"active"`
+	StartDate    Time         `json:"start_date,omitzero"`
+	EndDate      Time         `json:"end_date,omitzero"`
+	Type         *Type        `json:"type,omitempty"`
+	AuctionType  *AuctionType `json:"auction_type,omitempty"`
+	Buyer        *Buyer       `json:"buyer,omitempty"`
+	LastModified Time         `json:"last_modified,omitzero"`
+}
+
+// Floor returns the floor price in the deal's currency
+func (d *Deal) Floor() Money {
+	return Money{Amount: d.FloorPrice, Currency: d.Currency}
+}
+
+// DealPatch holds the deal fields to change with Patch. Only non-nil fields
+// are sent, so a field is cleared by pointing it at its zero value; fields
+// named in Null are sent as an explicit null.
+type DealPatch struct {
+	FloorPrice  *Decimal     `json:"floor_price,omitempty"`
+	Currency    *string      `json:"currency,omitempty"`
+	Code        *string      `json:"code,omitempty"`
+	Name        *string      `json:"name,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	StartDate   *Time        `json:"start_date,omitempty"`
+	EndDate     *Time        `json:"end_date,omitempty"`
 	Type        *Type        `json:"type,omitempty"`
 	AuctionType *AuctionType `json:"auction_type,omitempty"`
 	Buyer       *Buyer       `json:"buyer,omitempty"`
-}
 
-type dealResponse struct {
-	*http.Response
-	Obj struct {
-		Deal    `json:"deal,omitempty"`
-		Deals   []Deal `json:"deals,omitempty"`
-		Error   string `json:"error"`
-		Status  string `json:"status"`
-		Service string `json:"service"`
-		Rate    Rate   `json:"dbg_info"`
-	} `json:"response"`
+	Null []string `json:"-"`
 }
 
+func (d *Deal) objectID() *int64     { return &d.ID }
+func (d *Deal) objectVersion() *Time { return &d.LastModified }
+
 // Get a deal from the deal service by ID
 func (s *DealService) Get(dealID int64) (*Deal, error) {
-	path := fmt.Sprintf("deal?id=%d", dealID)
-	req, err := s.client.newRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	r := &dealResponse{}
-	_, err = s.client.do(req, r)
-	if err != nil {
-		return nil, err
-	}
+	return s.svc.Get(withID(nil, dealID))
+}
 
-	deal := &r.Obj.Deal
-	return deal, nil
+// GetByCode gets a deal by its code. It returns a *NotFoundError if no
+// deal has the code.
+func (s *DealService) GetByCode(code string) (*Deal, error) {
+	return getByCode(s.svc, nil, code)
 }
 
 // List available deals from your AppNexus console
 func (s *DealService) List() ([]Deal, *Response, error) {
-	req, err := s.client.newRequest("GET", "deal", nil)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	deals := &dealResponse{}
-	resp, err := s.client.do(req, deals)
-	if err != nil {
-		return nil, resp, err
-	}
+	return s.svc.List(nil, nil)
+}
 
-	return deals.Obj.Deals, resp, err
+// All iterates over every deal
+func (s *DealService) All() iter.Seq2[Deal, error] {
+	return s.svc.All(nil)
 }
 
 // Add a new deal
 func (s *DealService) Add(item *Deal) (*Response, error) {
-
-	data := struct {
-		Deal `json:"deal"`
-	}{*item}
-
-	req, err := s.client.newRequest("POST", "deal", data)
-
-	if err != nil {
-		return nil, err
-	}
-
-	result := &Response{}
-	resp, err := s.client.do(req, result)
-	if err != nil {
-		return resp, err
-	}
-
-	item.ID, _ = result.Obj.ID.Int64()
-	return result, nil
+	return addObject(s.svc, nil, item)
 }
 
-// Update an existing deal with new data
+// Update an existing deal with new data. With Client.SafeUpdates set the
+// update fails with a *ConflictError if the deal was modified since item was
+// read.
 func (s *DealService) Update(item Deal) (*Response, error) {
+	return updateObject(s.svc, nil, item, s.client.SafeUpdates)
+}
 
-	data := struct {
-		Deal `json:"deal"`
-	}{item}
+// UpdateWithMerge safely updates the deal, and on conflict calls merge with
+// the current version to reapply the caller's changes before retrying, up to
+// attempts times
+func (s *DealService) UpdateWithMerge(item Deal, merge func(current Deal) (Deal, error), attempts int) (*Response, error) {
+	return updateWithMerge(s.svc, nil, item, merge, attempts)
+}
 
-	if item.ID < 1 {
-		return nil, errors.New("Update Deal requires a deal to have an ID already")
+// Patch sends only the fields set in patch to the specified deal
+func (s *DealService) Patch(dealID int64, patch DealPatch) (*Response, error) {
+	if dealID < 1 {
+		return nil, errors.New("Patch Deal requires a deal ID")
 	}
 
-	req, err := s.client.newRequest("PUT", fmt.Sprintf("deal?id=%d", item.ID), data)
-
-	if err != nil {
-		return nil, err
-	}
+	return s.svc.Patch(withID(nil, dealID), patch, patch.Null)
+}
 
-	result := &Response{}
-	resp, err := s.client.do(req, result)
-	if err != nil {
-		return resp, err
-	}
+// UpdateByCode updates the deal with the given code, looking up its ID
+func (s *DealService) UpdateByCode(code string, item Deal) (*Response, error) {
+	return updateByCode(s.svc, nil, code, item, s.client.SafeUpdates)
+}
 
-	return result, nil
+// DeleteByCode deletes the deal with the given code
+func (s *DealService) DeleteByCode(code string) error {
+	return deleteByCode(s.svc, nil, code)
 }
 
 // Delete the specified deal
 func (s *DealService) Delete(dealID int64) error {
-	req, err := s.client.newRequest("DELETE", fmt.Sprintf("deal?id=%d", dealID), nil)
-	if err != nil {
-		return err
-	}
+	return s.svc.deleteObjects(nil, []int64{dealID})
+}
 
-	_, err = s.client.do(req, nil)
+// BulkDelete deletes several deals in a single request
+func (s *DealService) BulkDelete(dealIDs []int64) error {
+	return s.svc.deleteObjects(nil, dealIDs)
+}
+
+// Deactivate the specified deal instead of deleting it
+func (s *DealService) Deactivate(dealID int64) error {
+	_, err := s.Patch(dealID, DealPatch{Active: Bool(false)})
 	return err
 }
+
+// Meta returns the meta of the deal service, describing the fields of its
+// objects
+func (s *DealService) Meta() (*ServiceMeta, error) {
+	return s.svc.Meta()
+}
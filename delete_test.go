@@ -0,0 +1,68 @@
+package appnexus
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestDeleteRequests(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var method, uri, body string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, uri, body = r.Method, r.URL.RequestURI(), string(data)
+	}
+	for _, path := range []string{"/segment/1", "/deal", "/site", "/placement", "/publisher"} {
+		mux.HandleFunc(path, handler)
+	}
+
+	tests := []struct {
+		call   func() error
+		method string
+		uri    string
+		body   string
+	}{
+		{func() error { return client.Segments.Delete(1, 4) }, "DELETE", "/segment/1?id=4", ""},
+		{func() error { return client.Segments.BulkDelete(1, []int64{4, 5}) }, "DELETE", "/segment/1?id=4,5", ""},
+		{func() error { return client.Segments.Deactivate(1, 4) }, "PUT", "/segment/1?id=4", `{"segment":{"active":false}}` + "\n"},
+		{func() error { return client.Deals.Delete(7) }, "DELETE", "/deal?id=7", ""},
+		{func() error { return client.Deals.BulkDelete([]int64{7, 8, 9}) }, "DELETE", "/deal?id=7,8,9", ""},
+		{func() error { return client.Deals.Deactivate(7) }, "PUT", "/deal?id=7", `{"deal":{"active":false}}` + "\n"},
+		{func() error { return client.Sites.Delete(3, 2) }, "DELETE", "/site?id=3&publisher_id=2", ""},
+		{func() error { return client.Sites.BulkDelete([]int64{3, 4}, 2) }, "DELETE", "/site?id=3,4&publisher_id=2", ""},
+		{func() error { return client.Sites.Deactivate(3, 2) }, "PUT", "/site?id=3&publisher_id=2", `{"site":{"state":"inactive"}}` + "\n"},
+		{func() error { return client.Placements.Delete(6, 2) }, "DELETE", "/placement?id=6&publisher_id=2", ""},
+		{func() error { return client.Placements.BulkDelete([]int64{6, 7}, 2) }, "DELETE", "/placement?id=6,7&publisher_id=2", ""},
+		{func() error { return client.Placements.Deactivate(6, 2) }, "PUT", "/placement?id=6&publisher_id=2", `{"placement":{"state":"inactive"}}` + "\n"},
+		{func() error { return client.Publishers.Delete(2) }, "DELETE", "/publisher?id=2", ""},
+		{func() error { return client.Publishers.BulkDelete([]int64{2, 3}) }, "DELETE", "/publisher?id=2,3", ""},
+		{func() error { return client.Publishers.Deactivate(2) }, "PUT", "/publisher?id=2", `{"publisher":{"state":"inactive"}}` + "\n"},
+	}
+
+	for _, test := range tests {
+		method, uri, body = "", "", ""
+		if err := test.call(); err != nil {
+			t.Errorf("%s %s returned error: %v", test.method, test.uri, err)
+			continue
+		}
+
+		if method != test.method || uri != test.uri || body != test.body {
+			t.Errorf("sent %s %s %q, expected %s %s %q", method, uri, body, test.method, test.uri, test.body)
+		}
+	}
+}
+
+func TestDeleteRequiresID(t *testing.T) {
+	c, _ := NewClient("http://sand.api.appnexus.com/")
+
+	if err := c.Segments.Delete(1, 0); err == nil {
+		t.Errorf("Segments.Delete accepted a zero ID")
+	}
+
+	if err := c.Deals.BulkDelete(nil); err == nil {
+		t.Errorf("Deals.BulkDelete accepted no IDs")
+	}
+}
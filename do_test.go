@@ -0,0 +1,223 @@
+package appnexus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// segmentPage returns a list response of n segments
+func segmentPage(n int) []byte {
+	segments := make([]string, n)
+	for i := range segments {
+		segments[i] = fmt.Sprintf(`{"id":%d,"code":"code-%d","state":"active","short_name":"Segment %d","description":"A benchmark segment","member_id":1,"category":"benchmark","price":0,"expire_minutes":43200,"enable_rm_piggyback":false,"last_modified":"2018-01-01 00:00:00"}`, i+1, i, i)
+	}
+
+	return []byte(fmt.Sprintf(`{"response":{"status":"OK","count":%d,"start_element":0,"num_elements":%d,"segments":[%s],"dbg_info":{"reads":1,"read_limit":100}}}`, n, n, strings.Join(segments, ",")))
+}
+
+func benchmarkSegmentsList(b *testing.B, n int) {
+	setup()
+	defer teardown()
+
+	page := segmentPage(n)
+	mux.HandleFunc("/segment/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(page)
+	})
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(page)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		segments, _, err := client.Segments.List(1, nil)
+		if err != nil || len(segments) != n {
+			b.Fatalf("Segments.List returned %d segments, %v", len(segments), err)
+		}
+	}
+}
+
+func BenchmarkDo_List100(b *testing.B)  { benchmarkSegmentsList(b, 100) }
+func BenchmarkDo_List1000(b *testing.B) { benchmarkSegmentsList(b, 1000) }
+
+func BenchmarkDo_Writer(b *testing.B) {
+	setup()
+	defer teardown()
+
+	page := segmentPage(1000)
+	mux.HandleFunc("/segment/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(page)
+	})
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(page)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		req, err := client.newRequest("GET", "segment/1", nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if _, err := client.do(req, ioutil.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCheckResponse(b *testing.B) {
+	c, _ := NewClient("http://sand.api.appnexus.com/")
+	page := segmentPage(1000)
+	r := &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(nil))}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(page)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := c.checkResponse(r, page); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// decodeReadAllTwice is how do decoded a response before the status fields
+// were embedded in the typed responses: the body was read in full, decoded
+// into a *Response for the status and decoded again into v. It is the
+// baseline BenchmarkDecode_SingleDecode is compared with.
+func decodeReadAllTwice(r io.Reader, v interface{}) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &Response{}); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func benchmarkDecode(b *testing.B, decode func(r io.Reader, v interface{}) error) {
+	page := segmentPage(1000)
+	segments := NewService[Segment](nil, "segment", "segments", nil)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(page)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		r := segments.newResponse()
+		if err := decode(bytes.NewReader(page), r.Interface()); err != nil {
+			b.Fatal(err)
+		}
+
+		if _, list := segments.objects(r); len(list) != 1000 {
+			b.Fatalf("decoded %d segments", len(list))
+		}
+	}
+}
+
+func BenchmarkDecode_SingleDecode(b *testing.B) {
+	c, _ := NewClient("http://sand.api.appnexus.com/")
+	r := &http.Response{StatusCode: http.StatusOK}
+
+	benchmarkDecode(b, func(body io.Reader, v interface{}) error {
+		_, err := c.parseResponse(r, body, v)
+		return err
+	})
+}
+
+func BenchmarkDecode_ReadAllTwice(b *testing.B) {
+	benchmarkDecode(b, decodeReadAllTwice)
+}
+
+func TestParseResponse(t *testing.T) {
+	c, _ := NewClient("http://sand.api.appnexus.com/")
+	r := &http.Response{StatusCode: http.StatusOK}
+	body := `{"response":{"status":"OK","count":2,"service":"domain-list",
+        "domain-lists":[{"id":1},{"id":2}],"dbg_info":{"reads":3}}}`
+
+	lists := NewService[DomainList](nil, "domain-list", "domain-lists", nil)
+	typed := lists.newResponse()
+	resp, err := c.parseResponse(r, strings.NewReader(body), typed.Interface())
+	if err != nil {
+		t.Fatalf("parseResponse returned error: %v", err)
+	}
+	if _, list := lists.objects(typed); resp.Obj.Status != "OK" || resp.Obj.Count != 2 || resp.Obj.Rate.Reads != 3 || len(list) != 2 {
+		t.Errorf("parseResponse decoded status %+v and %+v", resp.Obj.responseStatus, list)
+	}
+	if embeddedStatus(typed.Interface()) == nil {
+		t.Errorf("the typed response of a service embeds no status fields")
+	}
+
+	raw := &RawResponse{}
+	resp, err = c.parseResponse(r, strings.NewReader(body), raw)
+	if err != nil {
+		t.Fatalf("parseResponse returned error: %v", err)
+	}
+	if resp.Obj.Service != "domain-list" || len(raw.Obj) != 5 || string(raw.Obj["status"]) != `"OK"` {
+		t.Errorf("parseResponse decoded status %+v and %v", resp.Obj.responseStatus, raw.Obj)
+	}
+
+	for _, v := range []interface{}{nil, raw} {
+		if resp, err := c.parseResponse(r, strings.NewReader(" "), v); resp != nil || err != nil {
+			t.Errorf("parseResponse returned %v, %v for an empty body into %T", resp, err, v)
+		}
+		if _, err := c.parseResponse(r, strings.NewReader(body[:40]), v); err == nil {
+			t.Errorf("parseResponse accepted a truncated body into %T", v)
+		}
+	}
+
+	// a retried call decodes into the same *Response again
+	full := &Response{}
+	c.parseResponse(r, strings.NewReader(`{"response":{"status":"error","error_id":"NOAUTH"}}`), full)
+	if _, err := c.parseResponse(r, strings.NewReader(`{"response":{"status":"OK"}}`), full); err != nil || full.Obj.ErrorID != "" {
+		t.Errorf("parseResponse kept the error of the previous response: %v", err)
+	}
+}
+
+func TestDo_Writer(t *testing.T) {
+	setup()
+	defer teardown()
+
+	page := segmentPage(3)
+	mux.HandleFunc("/segment/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(page)
+	})
+
+	req, _ := client.newRequest("GET", "segment/1", nil)
+	buf := new(bytes.Buffer)
+	if _, err := client.do(req, buf); err != nil {
+		t.Fatalf("do returned error: %v", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), page) {
+		t.Errorf("do wrote %s, expected the raw body", buf.String())
+	}
+}
+
+func TestDo_Response(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/segment/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(segmentPage(2))
+	})
+
+	req, _ := client.newRequest("GET", "segment/1", nil)
+	result := &Response{}
+	resp, err := client.do(req, result)
+	if err != nil {
+		t.Fatalf("do returned error: %v", err)
+	}
+
+	if resp != result || len(result.Obj.Segments) != 2 || result.Obj.Count != 2 || client.Rate.Reads != 1 {
+		t.Errorf("do returned %+v, expected the decoded *Response", resp.Obj)
+	}
+}
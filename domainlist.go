@@ -0,0 +1,216 @@
+package appnexus
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DomainListService handles all requests to the domain list service API
+type DomainListService struct {
+	*Response
+	client *Client
+	svc    *Service[DomainList]
+}
+
+// Domain list types
+const (
+	DomainListWhite = "white"
+	DomainListBlack = "black"
+)
+
+// DomainList is an allow or block list of domains within the AppNexus console
+type DomainList struct {
+	ID           int64    `json:"id,omitempty"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description,omitempty"`
+	Type         string   `json:"type,omitempty"`
+	Domains      []string `json:"domains"`
+	LastModified Time     `json:"last_modified,omitzero"`
+}
+
+func (l *DomainList) objectID() *int64     { return &l.ID }
+func (l *DomainList) objectVersion() *Time { return &l.LastModified }
+
+// Get a domain list from the domain list service by ID
+func (s *DomainListService) Get(listID int64) (*DomainList, error) {
+	return s.svc.Get(withID(nil, listID))
+}
+
+// List available domain lists from your AppNexus console
+func (s *DomainListService) List() ([]DomainList, *Response, error) {
+	return s.svc.List(nil, nil)
+}
+
+// Add a new domain list. Its domains are normalized and deduplicated first.
+func (s *DomainListService) Add(item *DomainList) (*Response, error) {
+	item.Domains = NormalizeDomains(item.Domains)
+
+	return addObject(s.svc, nil, item)
+}
+
+// Update an existing domain list, replacing its domains with item.Domains.
+// With Client.SafeUpdates set the update fails with a *ConflictError if the
+// list was modified since item was read.
+func (s *DomainListService) Update(item DomainList) (*Response, error) {
+	item.Domains = NormalizeDomains(item.Domains)
+
+	return updateObject(s.svc, nil, item, s.client.SafeUpdates)
+}
+
+// Delete the specified domain list
+func (s *DomainListService) Delete(listID int64) error {
+	return s.svc.deleteObjects(nil, []int64{listID})
+}
+
+// AddDomains appends domains to the list in a single request
+func (s *DomainListService) AddDomains(listID int64, domains []string) error {
+	domains = NormalizeDomains(domains)
+	if len(domains) == 0 {
+		return nil
+	}
+
+	data := struct {
+		DomainList struct {
+			Domains []string `json:"domains"`
+		} `json:"domain-list"`
+	}{}
+	data.DomainList.Domains = domains
+
+	_, err := s.svc.request("PUT", withID(url.Values{"append": {"true"}}, listID), data, nil)
+	return err
+}
+
+// RemoveDomains removes domains from the list. The API has no partial
+// removal, so the list is read and written back without them.
+func (s *DomainListService) RemoveDomains(listID int64, domains []string) error {
+	list, err := s.Get(listID)
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[string]bool)
+	for _, d := range NormalizeDomains(domains) {
+		remove[d] = true
+	}
+
+	removed := 0
+	kept := make([]string, 0, len(list.Domains))
+	for _, d := range NormalizeDomains(list.Domains) {
+		if remove[d] {
+			removed++
+			continue
+		}
+		kept = append(kept, d)
+	}
+
+	if removed == 0 {
+		return nil
+	}
+
+	list.Domains = kept
+	_, err = s.Update(*list)
+	return err
+}
+
+// Diff compares a local set of domains against the remote list, returning the
+// domains to add and to remove to make the remote list match
+func (s *DomainListService) Diff(listID int64, local []string) (add []string, remove []string, err error) {
+	list, err := s.Get(listID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	add, remove = DiffDomains(local, list.Domains)
+	return add, remove, nil
+}
+
+// NormalizeDomain lowercases a domain and strips what a pasted URL carries
+// around it: the space, a scheme, a path, query or fragment, a port and a
+// trailing dot. It keeps the host itself, such as a leading "www.", as lists
+// match domains exactly.
+func NormalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if _, host, ok := strings.Cut(domain, "://"); ok {
+		domain = host
+	}
+	if i := strings.IndexAny(domain, "/?#"); i >= 0 {
+		domain = domain[:i]
+	}
+	if i := strings.LastIndexByte(domain, ':'); i >= 0 && isDigits(domain[i+1:]) {
+		domain = domain[:i]
+	}
+
+	return strings.TrimRight(domain, ".")
+}
+
+// isDigits reports whether s is made of ASCII digits only
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NormalizeDomains normalizes every domain and returns them sorted without
+// duplicates or empty entries
+func NormalizeDomains(domains []string) []string {
+	return normalizeSet(domains, NormalizeDomain)
+}
+
+// DiffDomains returns the domains in local missing from remote and the
+// domains in remote missing from local, after normalizing both
+func DiffDomains(local, remote []string) (add []string, remove []string) {
+	return diffSets(NormalizeDomains(local), NormalizeDomains(remote))
+}
+
+// normalizeSet applies normalize to values and returns the sorted unique
+// non-empty results
+func normalizeSet(values []string, normalize func(string) string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+
+	for _, v := range values {
+		v = normalize(v)
+		if v == "" || seen[v] {
+			continue
+		}
+
+		seen[v] = true
+		out = append(out, v)
+	}
+
+	sort.Strings(out)
+	return out
+}
+
+// diffSets compares two sorted unique sets
+func diffSets(local, remote []string) (add []string, remove []string) {
+	in := func(set []string, v string) bool {
+		i := sort.SearchStrings(set, v)
+		return i < len(set) && set[i] == v
+	}
+
+	for _, v := range local {
+		if !in(remote, v) {
+			add = append(add, v)
+		}
+	}
+
+	for _, v := range remote {
+		if !in(local, v) {
+			remove = append(remove, v)
+		}
+	}
+
+	return add, remove
+}
+
+// Meta returns the meta of the domain list service, describing the fields of its
+// objects
+func (s *DomainListService) Meta() (*ServiceMeta, error) {
+	return s.svc.Meta()
+}
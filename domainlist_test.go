@@ -0,0 +1,129 @@
+package appnexus
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeDomains(t *testing.T) {
+	actual := NormalizeDomains([]string{
+		"www.Example.com",
+		"example.com",
+		" News.example.org ",
+		"EXAMPLE.com",
+		"https://Example.com/",
+		"example.com.",
+		"example.com:443",
+		"http://news.example.org:8080/path?q=1#top",
+		"",
+	})
+
+	expected := []string{"example.com", "news.example.org", "www.example.com"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("NormalizeDomains returned %v, expected %v", actual, expected)
+	}
+}
+
+func TestDiffDomains(t *testing.T) {
+	add, remove := DiffDomains([]string{"a.com", "B.com", "c.com"}, []string{"b.com", "d.com"})
+
+	if !reflect.DeepEqual(add, []string{"a.com", "c.com"}) || !reflect.DeepEqual(remove, []string{"d.com"}) {
+		t.Errorf("DiffDomains returned add %v remove %v", add, remove)
+	}
+}
+
+func TestDomainListService_AddDomains(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var uri, body string
+	mux.HandleFunc("/domain-list", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		uri, body = r.URL.RequestURI(), string(data)
+		fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+	})
+
+	if err := client.DomainLists.AddDomains(5, []string{" B.com", "a.com", "b.com"}); err != nil {
+		t.Fatalf("DomainLists.AddDomains returned error: %v", err)
+	}
+
+	if uri != "/domain-list?append=true&id=5" || body != `{"domain-list":{"domains":["a.com","b.com"]}}`+"\n" {
+		t.Errorf("DomainLists.AddDomains sent %s %s", uri, body)
+	}
+}
+
+func TestDomainListService_RemoveDomains(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var writes []string
+	mux.HandleFunc("/domain-list", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{"response":{"status":"OK","domain-list":{"id":5,"domains":["a.com","A.com","b.com"]}}}`)
+			return
+		}
+
+		data, _ := ioutil.ReadAll(r.Body)
+		writes = append(writes, string(data))
+		fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+	})
+
+	// The duplicate a.com entries normalize away, which removes nothing
+	if err := client.DomainLists.RemoveDomains(5, []string{"c.com"}); err != nil {
+		t.Fatalf("DomainLists.RemoveDomains returned error: %v", err)
+	}
+	if len(writes) != 0 {
+		t.Errorf("DomainLists.RemoveDomains sent %v when nothing was removed", writes)
+	}
+
+	if err := client.DomainLists.RemoveDomains(5, []string{"B.com"}); err != nil {
+		t.Fatalf("DomainLists.RemoveDomains returned error: %v", err)
+	}
+	if len(writes) != 1 || !strings.Contains(writes[0], `"domains":["a.com"]`) {
+		t.Errorf("DomainLists.RemoveDomains sent %v", writes)
+	}
+}
+
+func TestInventoryListService_Sync(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var writes []string
+	mux.HandleFunc("/inventory-list/9/item", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{"response":{"status":"OK","count":4,"inventory-list-items":[
+                {"id":1,"url":"keep.com"},
+                {"id":2,"url":"drop.com"},
+                {"id":3,"app_bundle_id":"com.example.game"},
+                {"id":4,"url":"https://Drop.com/"}]}}`)
+			return
+		}
+
+		data, _ := ioutil.ReadAll(r.Body)
+		writes = append(writes, r.Method+" "+r.URL.RequestURI()+" "+string(data))
+		fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+	})
+
+	local := []InventoryListItem{
+		{URL: "Keep.com "},
+		{URL: "new.com"},
+		{AppBundleID: "com.example.game"},
+		{AppBundleID: "123456789"},
+	}
+
+	if err := client.InventoryLists.Sync(9, local); err != nil {
+		t.Fatalf("InventoryLists.Sync returned error: %v", err)
+	}
+
+	expected := []string{
+		"DELETE /inventory-list/9/item?id=2,4 ",
+		`POST /inventory-list/9/item {"inventory-list-items":[{"url":"new.com"},{"app_bundle_id":"123456789"}]}` + "\n",
+	}
+	if !reflect.DeepEqual(writes, expected) {
+		t.Errorf("InventoryLists.Sync sent %q, expected %q", writes, expected)
+	}
+}
@@ -77,7 +77,7 @@ func main() {
 
 	// Delete the test segment:
 	fmt.Print("\n\nDelete the ", color.YellowString(newSegment.ShortName), " test segment")
-	err = c.Segments.Delete(member.ID, newSegment)
+	err = c.Segments.Delete(member.ID, newSegment.ID)
 	if err != nil {
 		color.Red(" FAILED\n" + err.Error())
 		os.Exit(7)
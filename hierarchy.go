@@ -0,0 +1,417 @@
+package appnexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// hierarchyVersion is the format version written by ExportHierarchy
+const hierarchyVersion = 1
+
+// HierarchyDocument is a portable description of publishers with their
+// sites and placements. Objects carry symbolic references instead of IDs so
+// that the document can be imported into another member. The reference of a
+// site or placement is scoped by its parent's, such as
+// "publisher:pub/site:news", as their codes are only unique within a
+// publisher.
+//
+// The document is read and written as JSON only; YAML is not supported, as
+// the package takes no YAML dependency. The indented JSON of WriteJSON is
+// valid YAML, so YAML tooling can read it, but a document edited as YAML has
+// to be converted back to JSON for ReadHierarchy.
+type HierarchyDocument struct {
+	Version    int            `json:"version"`
+	Publishers []PublisherDoc `json:"publishers"`
+}
+
+// PublisherDoc is an exported publisher. Placements holds the placements
+// which do not belong to a site.
+type PublisherDoc struct {
+	Ref        string         `json:"ref"`
+	Publisher  Publisher      `json:"publisher"`
+	Sites      []SiteDoc      `json:"sites,omitempty"`
+	Placements []PlacementDoc `json:"placements,omitempty"`
+}
+
+// SiteDoc is an exported site with its placements
+type SiteDoc struct {
+	Ref        string         `json:"ref"`
+	Site       Site           `json:"site"`
+	Placements []PlacementDoc `json:"placements,omitempty"`
+}
+
+// PlacementDoc is an exported placement
+type PlacementDoc struct {
+	Ref       string    `json:"ref"`
+	Placement Placement `json:"placement"`
+}
+
+// ImportOptions controls ImportHierarchy
+type ImportOptions struct {
+	// FailOnConflict stops the import before anything is created if any
+	// object's Code already exists in the target member. Otherwise the
+	// existing object is reused.
+	FailOnConflict bool
+}
+
+// ImportConflict is an object whose Code already exists in the target member
+type ImportConflict struct {
+	Kind       string
+	Ref        string
+	Code       string
+	ExistingID int64
+}
+
+// ImportReport describes the outcome of ImportHierarchy. IDs maps each
+// symbolic reference to the ID of the created or reused object.
+type ImportReport struct {
+	IDs       map[string]int64
+	Created   []string
+	Conflicts []ImportConflict
+}
+
+// ExportHierarchy walks the publishers with their sites and placements into
+// a portable document
+func (c *Client) ExportHierarchy(pubIDs ...int64) (*HierarchyDocument, error) {
+	doc := &HierarchyDocument{Version: hierarchyVersion}
+
+	for _, pubID := range pubIDs {
+		publisher, err := c.Publishers.Get(pubID)
+		if err != nil {
+			return nil, err
+		}
+
+		sites, err := c.Sites.ListAll(pubID)
+		if err != nil {
+			return nil, err
+		}
+
+		placements, err := c.Placements.ListAll(pubID)
+		if err != nil {
+			return nil, err
+		}
+
+		pubDoc := PublisherDoc{Ref: exportRef("", "publisher", publisher.Code, publisher.ID), Publisher: *publisher}
+		pubDoc.Publisher.ID = 0
+		pubDoc.Publisher.BasePaymentRuleID = 0
+		pubDoc.Publisher.LastModified = Time{}
+
+		siteIndex := make(map[int64]int, len(sites))
+		for _, site := range sites {
+			siteIndex[site.ID] = len(pubDoc.Sites)
+
+			siteDoc := SiteDoc{Ref: exportRef(pubDoc.Ref, "site", site.Code, site.ID), Site: site}
+			siteDoc.Site.ID = 0
+			siteDoc.Site.PublisherID = 0
+			siteDoc.Site.LastModified = Time{}
+			pubDoc.Sites = append(pubDoc.Sites, siteDoc)
+		}
+
+		for _, placement := range placements {
+			i, inSite := siteIndex[placement.SiteID]
+			parent := pubDoc.Ref
+			if inSite {
+				parent = pubDoc.Sites[i].Ref
+			}
+
+			pDoc := PlacementDoc{Ref: exportRef(parent, "placement", placement.Code, placement.ID), Placement: placement}
+			pDoc.Placement.ID = 0
+			pDoc.Placement.PublisherID = 0
+			pDoc.Placement.SiteID = 0
+			pDoc.Placement.LastModified = Time{}
+
+			if inSite {
+				pubDoc.Sites[i].Placements = append(pubDoc.Sites[i].Placements, pDoc)
+			} else {
+				pubDoc.Placements = append(pubDoc.Placements, pDoc)
+			}
+		}
+
+		doc.Publishers = append(doc.Publishers, pubDoc)
+	}
+
+	return doc, nil
+}
+
+// exportRef names an object by its code, falling back to its source ID,
+// within the reference of its parent if it has one
+func exportRef(parent, kind, code string, id int64) string {
+	ref := fmt.Sprintf("%s#%d", kind, id)
+	if code != "" {
+		ref = kind + ":" + code
+	}
+
+	if parent == "" {
+		return ref
+	}
+	return parent + "/" + ref
+}
+
+// checkRefs fails on the first reference the document uses twice, as the
+// import could not tell those objects apart
+func (d *HierarchyDocument) checkRefs() error {
+	seen := make(map[string]bool)
+	add := func(ref string) error {
+		if seen[ref] {
+			return fmt.Errorf("ImportHierarchy: duplicate reference %q", ref)
+		}
+		seen[ref] = true
+		return nil
+	}
+
+	for _, pub := range d.Publishers {
+		if err := add(pub.Ref); err != nil {
+			return err
+		}
+		for _, site := range pub.Sites {
+			if err := add(site.Ref); err != nil {
+				return err
+			}
+			for _, p := range site.Placements {
+				if err := add(p.Ref); err != nil {
+					return err
+				}
+			}
+		}
+		for _, p := range pub.Placements {
+			if err := add(p.Ref); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON writes the document as indented JSON
+func (d *HierarchyDocument) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// ReadHierarchy reads a document written by WriteJSON
+func ReadHierarchy(r io.Reader) (*HierarchyDocument, error) {
+	doc := &HierarchyDocument{}
+	if err := json.NewDecoder(r).Decode(doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Version != hierarchyVersion {
+		return nil, fmt.Errorf("ReadHierarchy: unsupported version %d", doc.Version)
+	}
+
+	return doc, nil
+}
+
+// ImportError is returned when ImportHierarchy fails after creating
+// objects. The objects it created have been deleted again in reverse order,
+// except those whose deletion failed as listed in RollbackErrors.
+type ImportError struct {
+	Ref            string
+	Err            error
+	RollbackErrors []error
+}
+
+func (e *ImportError) Error() string {
+	msg := fmt.Sprintf("ImportHierarchy: %s: %s", e.Ref, e.Err.Error())
+	if len(e.RollbackErrors) == 0 {
+		return msg + " (rolled back)"
+	}
+
+	errs := make([]string, len(e.RollbackErrors))
+	for i, err := range e.RollbackErrors {
+		errs[i] = err.Error()
+	}
+
+	return msg + "; rollback failed: " + strings.Join(errs, "; ")
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// ImportHierarchy recreates the document's publishers, sites and placements
+// through this client, remapping references to the new IDs. Objects whose
+// Code already exists are reported as conflicts and either reused or, with
+// FailOnConflict, stop the import before any write. Sites and placements
+// are only matched within their publisher. If a write fails, the objects
+// created so far are deleted again and an *ImportError is returned.
+func (c *Client) ImportHierarchy(doc *HierarchyDocument, opt ImportOptions) (*ImportReport, error) {
+	report := &ImportReport{IDs: make(map[string]int64)}
+	if err := doc.checkRefs(); err != nil {
+		return report, err
+	}
+
+	// existing holds the IDs of the objects whose code already exists by
+	// the reference of their publisher, as site and placement codes are
+	// only unique within a publisher
+	existing := make(map[string]map[string]int64)
+	check := func(pubRef, kind, ref, code string, get func(code string) (int64, error)) error {
+		if code == "" {
+			return nil
+		}
+
+		id, err := get(code)
+		if IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if existing[pubRef] == nil {
+			existing[pubRef] = make(map[string]int64)
+		}
+		existing[pubRef][ref] = id
+		report.Conflicts = append(report.Conflicts, ImportConflict{Kind: kind, Ref: ref, Code: code, ExistingID: id})
+		return nil
+	}
+
+	checkPlacements := func(placements []PlacementDoc, pubRef string, pubID int64) error {
+		for _, p := range placements {
+			err := check(pubRef, "placement", p.Ref, p.Placement.Code, func(code string) (int64, error) {
+				placement, err := c.Placements.GetByCode(code, pubID)
+				if err != nil {
+					return 0, err
+				}
+				return placement.ID, nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, pub := range doc.Publishers {
+		err := check(pub.Ref, "publisher", pub.Ref, pub.Publisher.Code, func(code string) (int64, error) {
+			publisher, err := c.Publishers.GetByCode(code)
+			if err != nil {
+				return 0, err
+			}
+			return publisher.ID, nil
+		})
+		if err != nil {
+			return report, err
+		}
+
+		// The sites and placements of a new publisher cannot conflict
+		pubID, ok := existing[pub.Ref][pub.Ref]
+		if !ok {
+			continue
+		}
+
+		for _, site := range pub.Sites {
+			err := check(pub.Ref, "site", site.Ref, site.Site.Code, func(code string) (int64, error) {
+				site, err := c.Sites.GetByCode(code, pubID)
+				if err != nil {
+					return 0, err
+				}
+				return site.ID, nil
+			})
+			if err != nil {
+				return report, err
+			}
+
+			if err := checkPlacements(site.Placements, pub.Ref, pubID); err != nil {
+				return report, err
+			}
+		}
+
+		if err := checkPlacements(pub.Placements, pub.Ref, pubID); err != nil {
+			return report, err
+		}
+	}
+
+	if opt.FailOnConflict && len(report.Conflicts) > 0 {
+		return report, fmt.Errorf("ImportHierarchy: %d objects already exist", len(report.Conflicts))
+	}
+
+	type created struct {
+		ref    string
+		delete func() error
+	}
+	var undo []created
+
+	fail := func(ref string, err error) (*ImportReport, error) {
+		ierr := &ImportError{Ref: ref, Err: err}
+		for i := len(undo) - 1; i >= 0; i-- {
+			if rerr := undo[i].delete(); rerr != nil {
+				ierr.RollbackErrors = append(ierr.RollbackErrors, fmt.Errorf("delete %s: %s", undo[i].ref, rerr.Error()))
+			}
+		}
+
+		return report, ierr
+	}
+
+	addPlacement := func(p PlacementDoc, pubRef string, pubID, siteID int64) error {
+		if id, ok := existing[pubRef][p.Ref]; ok {
+			report.IDs[p.Ref] = id
+			return nil
+		}
+
+		placement := p.Placement
+		placement.PublisherID = pubID
+		placement.SiteID = siteID
+		if _, err := c.Placements.Add(&placement); err != nil {
+			return err
+		}
+
+		id := placement.ID
+		report.IDs[p.Ref] = id
+		report.Created = append(report.Created, p.Ref)
+		undo = append(undo, created{p.Ref, func() error { return c.Placements.Delete(id, pubID) }})
+		return nil
+	}
+
+	for _, pubDoc := range doc.Publishers {
+		pubID, ok := existing[pubDoc.Ref][pubDoc.Ref]
+		if !ok {
+			publisher := pubDoc.Publisher
+			if _, err := c.Publishers.Add(&publisher); err != nil {
+				return fail(pubDoc.Ref, err)
+			}
+			pubID = publisher.ID
+			report.Created = append(report.Created, pubDoc.Ref)
+
+			id := pubID
+			undo = append(undo, created{pubDoc.Ref, func() error { return c.Publishers.Delete(id) }})
+		}
+		report.IDs[pubDoc.Ref] = pubID
+
+		for _, siteDoc := range pubDoc.Sites {
+			siteID, ok := existing[pubDoc.Ref][siteDoc.Ref]
+			if !ok {
+				site := siteDoc.Site
+				site.PublisherID = pubID
+				if _, err := c.Sites.Add(&site); err != nil {
+					return fail(siteDoc.Ref, err)
+				}
+				siteID = site.ID
+				report.Created = append(report.Created, siteDoc.Ref)
+
+				id, pub := siteID, pubID
+				undo = append(undo, created{siteDoc.Ref, func() error { return c.Sites.Delete(id, pub) }})
+			}
+			report.IDs[siteDoc.Ref] = siteID
+
+			for _, p := range siteDoc.Placements {
+				if err := addPlacement(p, pubDoc.Ref, pubID, siteID); err != nil {
+					return fail(p.Ref, err)
+				}
+			}
+		}
+
+		for _, p := range pubDoc.Placements {
+			if err := addPlacement(p, pubDoc.Ref, pubID, 0); err != nil {
+				return fail(p.Ref, err)
+			}
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,258 @@
+package appnexus
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestHierarchyExportImport(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/publisher", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"status":"OK","count":1,"publisher":{"id":2,"code":"pub","name":"Pub","base_payment_rule_id":31}}}`)
+	})
+	mux.HandleFunc("/site", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"status":"OK","count":1,"sites":[{"id":3,"publisher_id":2,"name":"Site"}]}}`)
+	})
+	mux.HandleFunc("/placement", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"status":"OK","count":2,"placements":[{"id":6,"publisher_id":2,"site_id":3,"code":"lb","name":"Leaderboard"},{"id":7,"publisher_id":2,"name":"Default"}]}}`)
+	})
+
+	doc, err := client.ExportHierarchy(2)
+	if err != nil {
+		t.Fatalf("ExportHierarchy returned error: %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := doc.WriteJSON(buf); err != nil {
+		t.Fatalf("WriteJSON returned error: %v", err)
+	}
+	doc, err = ReadHierarchy(buf)
+	if err != nil {
+		t.Fatalf("ReadHierarchy returned error: %v", err)
+	}
+
+	pub := doc.Publishers[0]
+	if pub.Ref != "publisher:pub" || pub.Publisher.ID != 0 || pub.Publisher.BasePaymentRuleID != 0 {
+		t.Errorf("ExportHierarchy publisher %+v", pub)
+	}
+	if len(pub.Sites) != 1 || pub.Sites[0].Ref != "publisher:pub/site#3" || len(pub.Sites[0].Placements) != 1 || pub.Sites[0].Placements[0].Ref != "publisher:pub/site#3/placement:lb" {
+		t.Errorf("ExportHierarchy sites %+v", pub.Sites)
+	}
+	if len(pub.Placements) != 1 || pub.Placements[0].Ref != "publisher:pub/placement#7" {
+		t.Errorf("ExportHierarchy placements %+v", pub.Placements)
+	}
+
+	// Import into a server where the publisher and, under it, the placement
+	// code already exist
+	teardown()
+	setup()
+
+	var calls []string
+	mux.HandleFunc("/publisher", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		fmt.Fprint(w, `{"response":{"status":"OK","count":1,"publisher":{"id":20,"code":"pub"}}}`)
+	})
+	mux.HandleFunc("/site", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		fmt.Fprint(w, `{"response":{"status":"OK","id":30}}`)
+	})
+	mux.HandleFunc("/placement", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{"response":{"status":"OK","count":1,"placement":{"id":60,"code":"lb"}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"response":{"status":"OK","id":70}}`)
+	})
+
+	report, err := client.ImportHierarchy(doc, ImportOptions{FailOnConflict: true})
+	if err == nil || len(report.Conflicts) != 2 || len(report.Created) != 0 {
+		t.Fatalf("ImportHierarchy with FailOnConflict returned %+v, %v", report, err)
+	}
+
+	calls = nil
+	report, err = client.ImportHierarchy(doc, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportHierarchy returned error: %v", err)
+	}
+
+	expected := []ImportConflict{
+		{Kind: "publisher", Ref: "publisher:pub", Code: "pub", ExistingID: 20},
+		{Kind: "placement", Ref: "publisher:pub/site#3/placement:lb", Code: "lb", ExistingID: 60},
+	}
+	if !reflect.DeepEqual(report.Conflicts, expected) {
+		t.Errorf("ImportHierarchy conflicts %+v, expected %+v", report.Conflicts, expected)
+	}
+
+	ids := map[string]int64{
+		"publisher:pub":                     20,
+		"publisher:pub/site#3":              30,
+		"publisher:pub/site#3/placement:lb": 60,
+		"publisher:pub/placement#7":         70,
+	}
+	if !reflect.DeepEqual(report.IDs, ids) {
+		t.Errorf("ImportHierarchy IDs %v, expected %v", report.IDs, ids)
+	}
+
+	sent := []string{
+		"GET /publisher?code=pub",
+		"GET /placement?code=lb&publisher_id=20",
+		"POST /site?publisher_id=20",
+		"POST /placement?publisher_id=20",
+	}
+	if !reflect.DeepEqual(calls, sent) {
+		t.Errorf("ImportHierarchy sent\n%v\nexpected\n%v", calls, sent)
+	}
+}
+
+func TestHierarchySharedSiteCode(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/publisher", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		fmt.Fprintf(w, `{"response":{"status":"OK","count":1,"publisher":{"id":%s,"code":"pub%s"}}}`, id, id)
+	})
+	mux.HandleFunc("/site", func(w http.ResponseWriter, r *http.Request) {
+		pubID := r.URL.Query().Get("publisher_id")
+		fmt.Fprintf(w, `{"response":{"status":"OK","count":1,"sites":[{"id":%s0,"publisher_id":%s,"code":"news"}]}}`, pubID, pubID)
+	})
+	mux.HandleFunc("/placement", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"status":"OK","count":0,"placements":[]}}`)
+	})
+
+	doc, err := client.ExportHierarchy(1, 2)
+	if err != nil {
+		t.Fatalf("ExportHierarchy returned error: %v", err)
+	}
+	if doc.Publishers[0].Sites[0].Ref != "publisher:pub1/site:news" || doc.Publishers[1].Sites[0].Ref != "publisher:pub2/site:news" {
+		t.Errorf("ExportHierarchy site refs %q and %q", doc.Publishers[0].Sites[0].Ref, doc.Publishers[1].Sites[0].Ref)
+	}
+
+	// Only the first publisher and its site exist in the target member, so
+	// the second publisher gets a site of its own
+	teardown()
+	setup()
+
+	var calls []string
+	mux.HandleFunc("/publisher", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		switch {
+		case r.Method == "POST":
+			fmt.Fprint(w, `{"response":{"status":"OK","id":21}}`)
+		case r.URL.Query().Get("code") == "pub1":
+			fmt.Fprint(w, `{"response":{"status":"OK","count":1,"publisher":{"id":20,"code":"pub1"}}}`)
+		default:
+			fmt.Fprint(w, `{"response":{"status":"OK","count":0}}`)
+		}
+	})
+	mux.HandleFunc("/site", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		if r.Method == "POST" {
+			fmt.Fprint(w, `{"response":{"status":"OK","id":31}}`)
+			return
+		}
+		fmt.Fprint(w, `{"response":{"status":"OK","count":1,"site":{"id":30,"publisher_id":20,"code":"news"}}}`)
+	})
+
+	report, err := client.ImportHierarchy(doc, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportHierarchy returned error: %v", err)
+	}
+
+	ids := map[string]int64{
+		"publisher:pub1":           20,
+		"publisher:pub1/site:news": 30,
+		"publisher:pub2":           21,
+		"publisher:pub2/site:news": 31,
+	}
+	if !reflect.DeepEqual(report.IDs, ids) {
+		t.Errorf("ImportHierarchy IDs %v, expected %v", report.IDs, ids)
+	}
+
+	sent := []string{
+		"GET /publisher?code=pub1",
+		"GET /site?code=news&publisher_id=20",
+		"GET /publisher?code=pub2",
+		"POST /publisher?create_default_placement=false",
+		"POST /site?publisher_id=21",
+	}
+	if !reflect.DeepEqual(calls, sent) {
+		t.Errorf("ImportHierarchy sent\n%v\nexpected\n%v", calls, sent)
+	}
+}
+
+func TestHierarchyImportDuplicateRef(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("ImportHierarchy sent %s %s for a document with duplicate references", r.Method, r.URL)
+	})
+
+	doc := &HierarchyDocument{Version: hierarchyVersion, Publishers: []PublisherDoc{
+		{Ref: "publisher:a", Sites: []SiteDoc{{Ref: "site:news"}}},
+		{Ref: "publisher:b", Sites: []SiteDoc{{Ref: "site:news"}}},
+	}}
+
+	if _, err := client.ImportHierarchy(doc, ImportOptions{}); err == nil {
+		t.Errorf("ImportHierarchy accepted a document with duplicate references")
+	}
+}
+
+func TestHierarchyImportRollback(t *testing.T) {
+	setup()
+	defer teardown()
+
+	doc := &HierarchyDocument{Version: hierarchyVersion, Publishers: []PublisherDoc{{
+		Ref:       "publisher:pub",
+		Publisher: Publisher{Code: "pub", Name: "Pub"},
+		Sites: []SiteDoc{{
+			Ref:        "site:news",
+			Site:       Site{Name: "News"},
+			Placements: []PlacementDoc{{Ref: "placement#7", Placement: Placement{Name: "Leaderboard"}}},
+		}},
+	}}}
+
+	var writes []string
+	handler := func(id int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" {
+				fmt.Fprint(w, `{"response":{"status":"OK","count":0}}`)
+				return
+			}
+
+			writes = append(writes, r.Method+" "+r.URL.RequestURI())
+			if r.Method == "POST" && r.URL.Path == "/placement" {
+				fmt.Fprint(w, `{"response":{"status":"error","error_id":"SYNTAX","error":"bad placement"}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"response":{"status":"OK","id":%d}}`, id)
+		}
+	}
+	mux.HandleFunc("/publisher", handler(20))
+	mux.HandleFunc("/site", handler(30))
+	mux.HandleFunc("/placement", handler(70))
+
+	_, err := client.ImportHierarchy(doc, ImportOptions{})
+	ierr, ok := err.(*ImportError)
+	if !ok || ierr.Ref != "placement#7" || len(ierr.RollbackErrors) != 0 {
+		t.Fatalf("ImportHierarchy returned %v, expected a rolled back *ImportError", err)
+	}
+
+	expected := []string{
+		"POST /publisher?create_default_placement=false",
+		"POST /site?publisher_id=20",
+		"POST /placement?publisher_id=20&site_id=30",
+		"DELETE /site?id=30&publisher_id=20",
+		"DELETE /publisher?id=20",
+	}
+	if !reflect.DeepEqual(writes, expected) {
+		t.Errorf("ImportHierarchy sent\n%v\nexpected\n%v", writes, expected)
+	}
+}
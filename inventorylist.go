@@ -0,0 +1,255 @@
+package appnexus
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// InventoryListService handles all requests to the inventory list and
+// inventory list item service APIs
+type InventoryListService struct {
+	*Response
+	client *Client
+	svc    *Service[InventoryList]
+	items  *Service[InventoryListItem]
+}
+
+// Inventory list types
+const (
+	InventoryListAllow = "allowlist"
+	InventoryListBlock = "blocklist"
+)
+
+// InventoryList is an allow or block list of domains and apps within the
+// AppNexus console
+type InventoryList struct {
+	ID                int64  `json:"id,omitempty"`
+	Name              string `json:"name"`
+	Description       string `json:"description,omitempty"`
+	InventoryListType string `json:"inventory_list_type,omitempty"`
+	MemberID          int    `json:"member_id,omitempty"`
+	NumItems          int    `json:"num_items,omitempty"`
+	LastModified      Time   `json:"last_modified,omitzero"`
+}
+
+// InventoryListItem is a single domain or app bundle ID on an inventory list
+type InventoryListItem struct {
+	ID              int64  `json:"id,omitempty"`
+	URL             string `json:"url,omitempty"`
+	AppBundleID     string `json:"app_bundle_id,omitempty"`
+	IncludeChildren bool   `json:"include_children,omitempty"`
+	LastModified    Time   `json:"last_modified,omitzero"`
+}
+
+// key identifies the item for deduplication and diffing
+func (i InventoryListItem) key() string {
+	if i.AppBundleID != "" {
+		return "app:" + i.AppBundleID
+	}
+
+	return "url:" + i.URL
+}
+
+// newInventoryListService returns the service with the items of its lists
+// addressed under each list's path
+func newInventoryListService(c *Client) *InventoryListService {
+	itemPath := func(params url.Values) string {
+		list := params.Get("list_id")
+		params.Del("list_id")
+
+		return "inventory-list/" + list + "/item"
+	}
+
+	return &InventoryListService{
+		client: c,
+		svc:    NewService[InventoryList](c, "inventory-list", "inventory-lists", nil),
+		items:  NewService[InventoryListItem](c, "inventory-list-item", "inventory-list-items", itemPath),
+	}
+}
+
+func (l *InventoryList) objectID() *int64     { return &l.ID }
+func (l *InventoryList) objectVersion() *Time { return &l.LastModified }
+
+// inList scopes a call to the items of an inventory list
+func inList(listID int64) url.Values {
+	return url.Values{"list_id": {strconv.FormatInt(listID, 10)}}
+}
+
+// Get an inventory list by ID
+func (s *InventoryListService) Get(listID int64) (*InventoryList, error) {
+	return s.svc.Get(withID(nil, listID))
+}
+
+// List available inventory lists from your AppNexus console
+func (s *InventoryListService) List(opt *ListOptions) ([]InventoryList, *Response, error) {
+	return s.svc.List(nil, opt)
+}
+
+// Add a new inventory list
+func (s *InventoryListService) Add(item *InventoryList) (*Response, error) {
+	return addObject(s.svc, nil, item)
+}
+
+// Update an existing inventory list with new data. With Client.SafeUpdates
+// set the update fails with a *ConflictError if the list was modified since
+// item was read.
+func (s *InventoryListService) Update(item InventoryList) (*Response, error) {
+	return updateObject(s.svc, nil, item, s.client.SafeUpdates)
+}
+
+// Delete the specified inventory list
+func (s *InventoryListService) Delete(listID int64) error {
+	return s.svc.deleteObjects(nil, []int64{listID})
+}
+
+// Items lists every item on the inventory list, paging through the results
+func (s *InventoryListService) Items(listID int64) ([]InventoryListItem, error) {
+	return s.items.ListAll(inList(listID))
+}
+
+// AddItems adds items to the inventory list in a single request. Domains
+// are normalized and duplicates dropped first.
+func (s *InventoryListService) AddItems(listID int64, items []InventoryListItem) error {
+	items = NormalizeInventoryItems(items)
+	if len(items) == 0 {
+		return nil
+	}
+
+	data := struct {
+		Items []InventoryListItem `json:"inventory-list-items"`
+	}{items}
+
+	_, err := s.items.request("POST", inList(listID), data, nil)
+	return err
+}
+
+// AddDomains adds domains to the inventory list
+func (s *InventoryListService) AddDomains(listID int64, domains []string) error {
+	items := make([]InventoryListItem, len(domains))
+	for i, d := range domains {
+		items[i] = InventoryListItem{URL: d}
+	}
+
+	return s.AddItems(listID, items)
+}
+
+// AddApps adds app bundle IDs to the inventory list
+func (s *InventoryListService) AddApps(listID int64, bundleIDs []string) error {
+	items := make([]InventoryListItem, len(bundleIDs))
+	for i, b := range bundleIDs {
+		items[i] = InventoryListItem{AppBundleID: b}
+	}
+
+	return s.AddItems(listID, items)
+}
+
+// RemoveItems removes items from the inventory list by item ID in a single
+// request
+func (s *InventoryListService) RemoveItems(listID int64, itemIDs []int64) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	return s.items.deleteObjects(inList(listID), itemIDs)
+}
+
+// Diff compares local items against the remote list, returning the items to
+// add and the remote items, with their IDs, to remove
+func (s *InventoryListService) Diff(listID int64, local []InventoryListItem) (add []InventoryListItem, remove []InventoryListItem, err error) {
+	remote, err := s.Items(listID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	add, remove = DiffInventoryItems(local, remote)
+	return add, remove, nil
+}
+
+// Sync makes the remote list match local, adding and removing items in bulk
+func (s *InventoryListService) Sync(listID int64, local []InventoryListItem) error {
+	add, remove, err := s.Diff(listID, local)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, len(remove))
+	for i, item := range remove {
+		ids[i] = item.ID
+	}
+
+	if err := s.RemoveItems(listID, ids); err != nil {
+		return err
+	}
+
+	return s.AddItems(listID, add)
+}
+
+// NormalizeInventoryItems normalizes domains and trims app bundle IDs,
+// dropping empty items and duplicates while keeping the original order
+func NormalizeInventoryItems(items []InventoryListItem) []InventoryListItem {
+	seen := make(map[string]bool, len(items))
+	out := make([]InventoryListItem, 0, len(items))
+
+	for _, item := range items {
+		item, ok := normalizeInventoryItem(item)
+		if !ok || seen[item.key()] {
+			continue
+		}
+
+		seen[item.key()] = true
+		out = append(out, item)
+	}
+
+	return out
+}
+
+// normalizeInventoryItem normalizes a single item, reporting false if it is
+// empty
+func normalizeInventoryItem(item InventoryListItem) (InventoryListItem, bool) {
+	item.AppBundleID = strings.TrimSpace(item.AppBundleID)
+	if item.AppBundleID == "" {
+		item.URL = NormalizeDomain(item.URL)
+	}
+
+	return item, item.URL != "" || item.AppBundleID != ""
+}
+
+// DiffInventoryItems returns the local items missing from remote and the
+// remote items missing from local. Every copy of a remote item is removed,
+// so a duplicate on the remote list leaves none of its IDs behind.
+func DiffInventoryItems(local, remote []InventoryListItem) (add []InventoryListItem, remove []InventoryListItem) {
+	local = NormalizeInventoryItems(local)
+
+	want := make(map[string]bool, len(local))
+	for _, item := range local {
+		want[item.key()] = true
+	}
+
+	have := make(map[string]bool, len(remote))
+	for _, item := range remote {
+		item, ok := normalizeInventoryItem(item)
+		if !ok {
+			continue
+		}
+
+		have[item.key()] = true
+		if !want[item.key()] {
+			remove = append(remove, item)
+		}
+	}
+
+	for _, item := range local {
+		if !have[item.key()] {
+			add = append(add, item)
+		}
+	}
+
+	return add, remove
+}
+
+// Meta returns the meta of the inventory list service, describing the fields of its
+// objects
+func (s *InventoryListService) Meta() (*ServiceMeta, error) {
+	return s.svc.Meta()
+}
@@ -0,0 +1,341 @@
+package appnexus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultLookupTTL is how long lookup results are cached by default
+const defaultLookupTTL = 24 * time.Hour
+
+// Read-only reference services available through the LookupService
+const (
+	LookupCountry         = "country"
+	LookupRegion          = "region"
+	LookupCity            = "city"
+	LookupDMA             = "dma"
+	LookupLanguage        = "language"
+	LookupBrowser         = "browser"
+	LookupOperatingSystem = "operating-system-extended"
+	LookupDeviceModel     = "device-model"
+	LookupCarrier         = "carrier"
+	LookupCategory        = "category"
+	LookupBrand           = "brand"
+	LookupContentCategory = "content-category"
+)
+
+// lookupPlurals holds the key each lookup service lists its objects under
+var lookupPlurals = map[string]string{
+	LookupCountry:         "countries",
+	LookupRegion:          "regions",
+	LookupCity:            "cities",
+	LookupDMA:             "dmas",
+	LookupLanguage:        "languages",
+	LookupBrowser:         "browsers",
+	LookupOperatingSystem: "operating-systems-extended",
+	LookupDeviceModel:     "device-models",
+	LookupCarrier:         "carriers",
+	LookupCategory:        "categories",
+	LookupBrand:           "brands",
+	LookupContentCategory: "content-categories",
+}
+
+// LookupService reads the reference services used for targeting and
+// reporting. Every result is cached in Cache, so repeated lookups do not
+// count against the read limit.
+type LookupService struct {
+	client *Client
+	Cache  LookupCache
+}
+
+// Country is a country which can be targeted
+type Country struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+// Region is a state or province of a country
+type Region struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Code        string `json:"code"`
+	CountryCode string `json:"country_code"`
+	CountryName string `json:"country_name,omitempty"`
+}
+
+// City is a city of a region
+type City struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	RegionID    int64  `json:"region_id,omitempty"`
+	RegionCode  string `json:"region_code,omitempty"`
+	CountryCode string `json:"country_code"`
+	DMAID       int64  `json:"dma_id,omitempty"`
+}
+
+// DMA is a Nielsen designated market area
+type DMA struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Language is a browser language
+type Language struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Code string `json:"code,omitempty"`
+}
+
+// Browser is a web browser
+type Browser struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// OperatingSystem is an operating system version
+type OperatingSystem struct {
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	OSFamilyID     int64  `json:"os_family_id,omitempty"`
+	OSFamilyName   string `json:"os_family_name,omitempty"`
+	PlatformType   string `json:"platform_type,omitempty"`
+	VersionOrdinal int    `json:"version_ordinal,omitempty"`
+}
+
+// DeviceModel is a model of mobile or connected device
+type DeviceModel struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	DeviceMakeID int64  `json:"device_make_id,omitempty"`
+	DeviceType   string `json:"device_type,omitempty"`
+}
+
+// Carrier is a mobile carrier
+type Carrier struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	CountryCode string `json:"country_code,omitempty"`
+}
+
+// Category is an advertiser brand category
+type Category struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	IsSensitive bool   `json:"is_sensitive"`
+}
+
+// Brand is an advertiser brand
+type Brand struct {
+	ID                int64  `json:"id"`
+	Name              string `json:"name"`
+	URL               string `json:"url,omitempty"`
+	PrimaryCategoryID int64  `json:"primary_category_id,omitempty"`
+}
+
+// ContentCategory is a category describing publisher content
+type ContentCategory struct {
+	ID               int64  `json:"id"`
+	Name             string `json:"name"`
+	ParentCategoryID int64  `json:"parent_category_id,omitempty"`
+	IsSystem         bool   `json:"is_system"`
+}
+
+// LookupEntry is the ID and name of any lookup object, used by Search
+type LookupEntry struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Countries lists every country
+func (s *LookupService) Countries() ([]Country, error) {
+	var v []Country
+	return v, s.list(LookupCountry, nil, &v)
+}
+
+// Regions lists the regions of a country, or of every country if
+// countryCode is empty
+func (s *LookupService) Regions(countryCode string) ([]Region, error) {
+	var v []Region
+	return v, s.list(LookupRegion, countryFilter(countryCode), &v)
+}
+
+// Cities lists the cities of a country. The country is required, as the
+// cities of the world run to hundreds of pages.
+func (s *LookupService) Cities(countryCode string) ([]City, error) {
+	var v []City
+	return v, s.list(LookupCity, countryFilter(countryCode), &v)
+}
+
+// RegionCities lists the cities of a region
+func (s *LookupService) RegionCities(regionID int64) ([]City, error) {
+	var v []City
+	return v, s.list(LookupCity, url.Values{"region_id": {strconv.FormatInt(regionID, 10)}}, &v)
+}
+
+// DMAs lists every designated market area
+func (s *LookupService) DMAs() ([]DMA, error) {
+	var v []DMA
+	return v, s.list(LookupDMA, nil, &v)
+}
+
+// Languages lists every language
+func (s *LookupService) Languages() ([]Language, error) {
+	var v []Language
+	return v, s.list(LookupLanguage, nil, &v)
+}
+
+// Browsers lists every browser
+func (s *LookupService) Browsers() ([]Browser, error) {
+	var v []Browser
+	return v, s.list(LookupBrowser, nil, &v)
+}
+
+// OperatingSystems lists every operating system version
+func (s *LookupService) OperatingSystems() ([]OperatingSystem, error) {
+	var v []OperatingSystem
+	return v, s.list(LookupOperatingSystem, nil, &v)
+}
+
+// DeviceModels lists every device model
+func (s *LookupService) DeviceModels() ([]DeviceModel, error) {
+	var v []DeviceModel
+	return v, s.list(LookupDeviceModel, nil, &v)
+}
+
+// Carriers lists every mobile carrier
+func (s *LookupService) Carriers() ([]Carrier, error) {
+	var v []Carrier
+	return v, s.list(LookupCarrier, nil, &v)
+}
+
+// Categories lists every brand category
+func (s *LookupService) Categories() ([]Category, error) {
+	var v []Category
+	return v, s.list(LookupCategory, nil, &v)
+}
+
+// Brands lists every brand
+func (s *LookupService) Brands() ([]Brand, error) {
+	var v []Brand
+	return v, s.list(LookupBrand, nil, &v)
+}
+
+// ContentCategories lists every content category
+func (s *LookupService) ContentCategories() ([]ContentCategory, error) {
+	var v []ContentCategory
+	return v, s.list(LookupContentCategory, nil, &v)
+}
+
+// Search returns the entries of a lookup service whose name contains name,
+// ignoring case. filter narrows the service, such as country_code for
+// regions and cities, and may be nil except for cities, which need a
+// country_code or region_id.
+func (s *LookupService) Search(service string, filter url.Values, name string) ([]LookupEntry, error) {
+	var entries []LookupEntry
+	if err := s.list(service, filter, &entries); err != nil {
+		return nil, err
+	}
+
+	needle := strings.ToLower(strings.TrimSpace(name))
+	matches := make([]LookupEntry, 0)
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.Name), needle) {
+			matches = append(matches, e)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	return matches, nil
+}
+
+// ID returns the ID of the entry of a lookup service named exactly name,
+// ignoring case. It fails if there is no such entry or more than one.
+func (s *LookupService) ID(service string, filter url.Values, name string) (int64, error) {
+	matches, err := s.Search(service, filter, name)
+	if err != nil {
+		return 0, err
+	}
+
+	var found []LookupEntry
+	for _, e := range matches {
+		if strings.EqualFold(e.Name, strings.TrimSpace(name)) {
+			found = append(found, e)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return 0, fmt.Errorf("%s: no entry named %q", service, name)
+	case 1:
+		return found[0].ID, nil
+	}
+
+	return 0, fmt.Errorf("%s: %d entries named %q", service, len(found), name)
+}
+
+// list decodes every object of the service into out, from the cache when
+// possible. Cache keys include the endpoint, so clients of different
+// endpoints can share a cache.
+func (s *LookupService) list(service string, filter url.Values, out interface{}) error {
+	plural, ok := lookupPlurals[service]
+	if !ok {
+		return fmt.Errorf("Lookup: unsupported service %q", service)
+	}
+
+	if service == LookupCity && filter.Get("country_code") == "" && filter.Get("region_id") == "" {
+		return errors.New("Lookup: cities require a country_code or region_id filter")
+	}
+
+	key := s.client.EndPoint.String() + service
+	if len(filter) > 0 {
+		key += "?" + filter.Encode()
+	}
+
+	if s.Cache != nil {
+		if data, ok := s.Cache.Get(key); ok {
+			return json.Unmarshal(data, out)
+		}
+	}
+
+	items, err := s.fetchAll(service, plural, filter)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	if s.Cache != nil {
+		s.Cache.Set(key, data)
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+// fetchAll pages through a lookup service, returning the raw objects listed
+// under plural
+func (s *LookupService) fetchAll(service, plural string, filter url.Values) ([]json.RawMessage, error) {
+	all, err := NewService[json.RawMessage](s.client, service, plural, nil).ListAll(filter)
+	if all == nil && err == nil {
+		all = make([]json.RawMessage, 0)
+	}
+
+	return all, err
+}
+
+func countryFilter(countryCode string) url.Values {
+	if countryCode == "" {
+		return nil
+	}
+
+	return url.Values{"country_code": {countryCode}}
+}
@@ -0,0 +1,115 @@
+package appnexus
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestLookupService_Cache(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	mux.HandleFunc("/country", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"response":{"status":"OK","count":3,"countries":[
+            {"id":1,"name":"United Kingdom","code":"GB"},
+            {"id":2,"name":"United States","code":"US"},
+            {"id":3,"name":"France","code":"FR"}]}}`)
+	})
+
+	for i := 0; i < 2; i++ {
+		countries, err := client.Lookups.Countries()
+		if err != nil {
+			t.Fatalf("Lookups.Countries returned error: %v", err)
+		}
+
+		if len(countries) != 3 || countries[2].Code != "FR" {
+			t.Errorf("Lookups.Countries returned %+v", countries)
+		}
+	}
+
+	matches, err := client.Lookups.Search(LookupCountry, nil, "united")
+	if err != nil || len(matches) != 2 {
+		t.Errorf("Lookups.Search returned %+v, %v", matches, err)
+	}
+
+	if id, err := client.Lookups.ID(LookupCountry, nil, "france"); err != nil || id != 3 {
+		t.Errorf("Lookups.ID returned %d, %v", id, err)
+	}
+
+	if _, err := client.Lookups.ID(LookupCountry, nil, "Spain"); err == nil {
+		t.Errorf("Lookups.ID found a missing country")
+	}
+
+	if requests != 1 {
+		t.Errorf("Lookups sent %d requests, expected 1", requests)
+	}
+}
+
+func TestLookupService_Filter(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/city", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("country_code") != "FR" {
+			t.Errorf("city lookup sent %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"response":{"status":"OK","count":1,"cities":[{"id":9,"name":"Paris","country_code":"FR"}]}}`)
+	})
+
+	id, err := client.Lookups.ID(LookupCity, url.Values{"country_code": {"FR"}}, "Paris")
+	if err != nil || id != 9 {
+		t.Errorf("Lookups.ID returned %d, %v", id, err)
+	}
+}
+
+func TestLookupService_Keys(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/region", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"status":"OK","count":1,"countries":[{"id":1,"name":"France"}],"regions":[{"id":5,"name":"Bretagne","country_code":"FR"}]}}`)
+	})
+
+	// Objects are read from the service's own key, not any other list
+	regions, err := client.Lookups.Regions("FR")
+	if err != nil || len(regions) != 1 || regions[0].ID != 5 {
+		t.Fatalf("Lookups.Regions returned %+v, %v", regions, err)
+	}
+
+	if _, err := client.Lookups.Cities(""); err == nil {
+		t.Errorf("Lookups.Cities accepted no country")
+	}
+
+	// Clients of another endpoint sharing the cache do not see its entries
+	other, _ := NewClient("http://other.example.com/")
+	other.Lookups.Cache = client.Lookups.Cache
+	if _, ok := other.Lookups.Cache.Get(other.EndPoint.String() + "region?country_code=FR"); ok {
+		t.Errorf("cache entry was not scoped by endpoint")
+	}
+	if _, ok := client.Lookups.Cache.Get(client.EndPoint.String() + "region?country_code=FR"); !ok {
+		t.Errorf("cache entry missing for the client's endpoint")
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	c := NewDiskCache(t.TempDir(), time.Hour)
+
+	if _, ok := c.Get("city?country_code=FR"); ok {
+		t.Errorf("DiskCache.Get found a missing key")
+	}
+
+	c.Set("city?country_code=FR", []byte(`[]`))
+	if data, ok := c.Get("city?country_code=FR"); !ok || string(data) != "[]" {
+		t.Errorf("DiskCache.Get returned %s, %v", data, ok)
+	}
+
+	c.TTL = 0
+	if _, ok := c.Get("city?country_code=FR"); ok {
+		t.Errorf("DiskCache.Get returned an expired entry")
+	}
+}
@@ -3,6 +3,7 @@ package appnexus
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // MemberService handles all requests to the member service API
@@ -21,7 +22,7 @@ type Member struct {
 	EntityType                         string        `json:"entity_type"`
 	ResellingExposure                  string        `json:"reselling_exposure"`
 	ResellingExposedOn                 string        `json:"reselling_exposed_on"`
-	LastModified                       string        `json:"last_modified"`
+	LastModified                       Time          `json:"last_modified"`
 	Timezone                           string        `json:"timezone"`
 	UseInsertionOrders                 bool          `json:"use_insertion_orders"`
 	ExposeOptimizationLevers           bool          `json:"expose_optimization_levers"`
@@ -76,6 +77,15 @@ type Member struct {
 	StandardSizes                      []interface{} `json:"standard_sizes"`
 }
 
+// Location loads the member's timezone, for reading its Time values
+func (m *Member) Location() (*time.Location, error) {
+	if m.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	return time.LoadLocation(m.Timezone)
+}
+
 type memberResponse struct {
 	*http.Response
 	Obj struct {
@@ -121,3 +131,9 @@ func (s *MemberService) GetDefault() (*Member, error) {
 	s.client.MemberID = member.ID
 	return member, nil
 }
+
+// Meta returns the meta of the member service, describing the fields of its
+// objects
+func (s *MemberService) Meta() (*ServiceMeta, error) {
+	return s.client.Meta("member")
+}
@@ -2,7 +2,10 @@ package appnexus
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -40,3 +43,160 @@ func TestMemberService_Get(t *testing.T) {
 		t.Errorf("Members.Get returned %+v, expected %+v", actual, expected)
 	}
 }
+
+func TestClient_ForMember(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	record := func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		fmt.Fprint(w, `{"response":{"status":"OK","count":0}}`)
+	}
+	mux.HandleFunc("/publisher", record)
+	mux.HandleFunc("/segment/7", record)
+	mux.HandleFunc("/site", record)
+
+	client.MemberID = 1
+	scoped := client.ForMember(7)
+
+	if _, _, err := scoped.Publishers.List(); err != nil {
+		t.Fatalf("Publishers.List returned error: %v", err)
+	}
+	if _, _, err := scoped.Segments.List(0, nil); err != nil {
+		t.Fatalf("Segments.List returned error: %v", err)
+	}
+	if _, err := scoped.Sites.Get(3, 2); err != nil {
+		t.Fatalf("Sites.Get returned error: %v", err)
+	}
+	if _, _, err := client.Publishers.List(); err != nil {
+		t.Fatalf("Publishers.List returned error: %v", err)
+	}
+
+	expected := []string{
+		"GET /publisher?member_id=7",
+		"GET /segment/7?member_id=7",
+		"GET /site?id=3&member_id=7&publisher_id=2",
+		"GET /publisher",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("ForMember sent\n%v\nexpected\n%v", calls, expected)
+	}
+
+	if client.MemberID != 1 || scoped.MemberID != 7 {
+		t.Errorf("ForMember changed the client's member to %d", client.MemberID)
+	}
+}
+
+func TestClient_ForMemberSharesSession(t *testing.T) {
+	setup()
+	defer teardown()
+
+	logins := 0
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "switch_to_user") {
+			fmt.Fprint(w, `{"response":{"status":"OK","token":"switched"}}`)
+			return
+		}
+
+		logins++
+		http.SetCookie(w, &http.Cookie{Name: "authn", Value: fmt.Sprintf("login-%d", logins)})
+		fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+	})
+
+	var tokens []string
+	mux.HandleFunc("/publisher", func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		tokens = append(tokens, token)
+		if token == "login-1" {
+			fmt.Fprint(w, `{"response":{"error_id":"NOAUTH","error":"token expired"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"response":{"status":"OK","count":0}}`)
+	})
+
+	if err := client.Login("network", "secret"); err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+
+	// The view logs in again after NOAUTH, and the parent then uses the new
+	// token rather than the expired one
+	scoped := client.ForMember(7)
+	if _, _, err := scoped.Publishers.List(); err != nil {
+		t.Fatalf("Publishers.List returned error: %v", err)
+	}
+	if _, _, err := client.Publishers.List(); err != nil {
+		t.Fatalf("Publishers.List returned error: %v", err)
+	}
+
+	expected := []string{"login-1", "login-2", "login-2"}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("requests were made with tokens %v, expected %v", tokens, expected)
+	}
+
+	if err := scoped.SwitchUser(42); err != nil {
+		t.Fatalf("SwitchUser returned error: %v", err)
+	}
+	if client.SwitchedUser() != 42 {
+		t.Errorf("SwitchedUser is %d on the client after switching a view", client.SwitchedUser())
+	}
+}
+
+func TestClient_SwitchMember(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "switch_to_user") {
+			calls = append(calls, "switch "+r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"response":{"status":"OK","token":"member-7"}}`)
+			return
+		}
+
+		calls = append(calls, "login")
+		http.SetCookie(w, &http.Cookie{Name: "authn", Value: fmt.Sprintf("login-%d", len(calls))})
+		fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+	})
+	mux.HandleFunc("/publisher", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Header.Get("Authorization")+" "+r.URL.RequestURI())
+		fmt.Fprint(w, `{"response":{"status":"OK","count":0}}`)
+	})
+
+	if _, err := client.SwitchMember(7, 42); err == nil {
+		t.Errorf("SwitchMember returned no error before Login")
+	}
+	calls = nil
+
+	if err := client.Login("network", "secret"); err != nil {
+		t.Fatalf("Login returned error: %v", err)
+	}
+
+	member, err := client.SwitchMember(7, 42)
+	if err != nil {
+		t.Fatalf("SwitchMember returned error: %v", err)
+	}
+	if _, _, err := member.Publishers.List(); err != nil {
+		t.Fatalf("Publishers.List returned error: %v", err)
+	}
+	if _, _, err := client.Publishers.List(); err != nil {
+		t.Fatalf("Publishers.List returned error: %v", err)
+	}
+
+	expected := []string{
+		"login",
+		"login",
+		"switch login-2",
+		"member-7 /publisher?member_id=7",
+		"login-1 /publisher",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("SwitchMember sent\n%v\nexpected\n%v", calls, expected)
+	}
+
+	if client.SwitchedUser() != 0 || member.SwitchedUser() != 42 {
+		t.Errorf("SwitchedUser is %d on the client and %d on the member", client.SwitchedUser(), member.SwitchedUser())
+	}
+}
@@ -0,0 +1,251 @@
+package appnexus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Field types reported by the meta of a service
+const (
+	MetaInt            = "int"
+	MetaDouble         = "double"
+	MetaMoney          = "money"
+	MetaString         = "string"
+	MetaBoolean        = "boolean"
+	MetaDate           = "date"
+	MetaTimestamp      = "timestamp"
+	MetaEnum           = "enum"
+	MetaObject         = "object"
+	MetaArrayOfObjects = "array of objects"
+	MetaArrayOfStrings = "array of strings"
+	MetaArrayOfInts    = "array of ints"
+)
+
+// ServiceMeta describes the fields of a service's objects, as reported by
+// its meta call
+type ServiceMeta struct {
+	Fields []MetaField `json:"fields"`
+}
+
+// MetaField describes one field of a service's objects. Fields of objects
+// and arrays of objects are described in Fields.
+type MetaField struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	ReadOnly    bool        `json:"read_only,omitempty"`
+	RequiredOn  string      `json:"required_on,omitempty"`
+	SortBy      bool        `json:"sort_by,omitempty"`
+	FilterBy    bool        `json:"filter_by,omitempty"`
+	MaxLength   int         `json:"max_length,omitempty"`
+	EnumValues  []string    `json:"enum_values,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Fields      []MetaField `json:"fields,omitempty"`
+}
+
+// Field returns the named field, or nil
+func (m *ServiceMeta) Field(name string) *MetaField {
+	for i := range m.Fields {
+		if m.Fields[i].Name == name {
+			return &m.Fields[i]
+		}
+	}
+
+	return nil
+}
+
+// ParseMeta reads the response of a meta call, such as a fixture saved from
+// GET /segment/meta
+func ParseMeta(r io.Reader) (*ServiceMeta, error) {
+	data := struct {
+		Obj ServiceMeta `json:"response"`
+	}{}
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return &data.Obj, nil
+}
+
+// Meta fetches the meta of any service, such as "creative", describing the
+// fields of its objects
+func (c *Client) Meta(service string) (*ServiceMeta, error) {
+	req, err := c.newRequest("GET", service+"/meta", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		Obj ServiceMeta `json:"response"`
+	}{}
+	if _, err := c.do(req, &data); err != nil {
+		return nil, err
+	}
+
+	return &data.Obj, nil
+}
+
+// FieldError is a field of an object which its service's meta rejects.
+// Fields of nested objects are named by their path, such as sizes[0].width.
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Reason
+}
+
+// ValidationError is returned by writes when Client.Validate is set and the
+// object fails the meta of its service. Nothing is sent.
+type ValidationError struct {
+	Service string
+	Method  string
+	Fields  []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		reasons[i] = f.Error()
+	}
+
+	return fmt.Sprintf("AppNexus: invalid %s for %s: %s", e.Service, e.Method, strings.Join(reasons, "; "))
+}
+
+// Validate checks the JSON encoding of v, an object of the service, for a
+// write with method (POST, PUT or PATCH). It reports required fields which
+// are missing, values outside an enum or longer than the field's max length
+// and read-only fields which are set. Fields the meta does not describe are
+// ignored. With Client.Validate the services leave out the fields which only
+// address the object, such as last_modified, and the read-only fields of an
+// update, as an object read back carries them.
+func (m *ServiceMeta) Validate(method string, v interface{}) ([]FieldError, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	return validateFields(m.Fields, method, obj, ""), nil
+}
+
+// withoutReadOnly drops the read-only fields from obj and from the objects
+// nested in it
+func withoutReadOnly(fields []MetaField, obj map[string]json.RawMessage) {
+	for _, field := range fields {
+		value, ok := obj[field.Name]
+		if !ok {
+			continue
+		}
+
+		if field.ReadOnly {
+			delete(obj, field.Name)
+			continue
+		}
+
+		switch field.Type {
+		case MetaObject:
+			var nested map[string]json.RawMessage
+			if err := json.Unmarshal(value, &nested); err == nil && nested != nil {
+				withoutReadOnly(field.Fields, nested)
+				obj[field.Name], _ = json.Marshal(nested)
+			}
+
+		case MetaArrayOfObjects:
+			var list []map[string]json.RawMessage
+			if err := json.Unmarshal(value, &list); err == nil && list != nil {
+				for _, nested := range list {
+					withoutReadOnly(field.Fields, nested)
+				}
+				obj[field.Name], _ = json.Marshal(list)
+			}
+		}
+	}
+}
+
+// validateFields checks the fields of one object, naming them under prefix
+func validateFields(fields []MetaField, method string, obj map[string]json.RawMessage, prefix string) []FieldError {
+	var errs []FieldError
+
+	for _, field := range fields {
+		name := prefix + field.Name
+		value, set := obj[field.Name]
+		if set && string(value) == "null" {
+			set = false
+		}
+
+		if !set || string(value) == `""` {
+			if requiredOn(field, method) {
+				errs = append(errs, FieldError{Field: name, Reason: "is required on " + method})
+			}
+			continue
+		}
+
+		if field.ReadOnly && !zeroJSON(value) {
+			errs = append(errs, FieldError{Field: name, Reason: "is read-only"})
+			continue
+		}
+
+		switch field.Type {
+		case MetaEnum, MetaString:
+			var s string
+			if err := json.Unmarshal(value, &s); err != nil {
+				continue
+			}
+
+			if field.Type == MetaEnum && len(field.EnumValues) > 0 && !slices.Contains(field.EnumValues, s) {
+				errs = append(errs, FieldError{Field: name, Reason: fmt.Sprintf("must be one of %s, not %q", strings.Join(field.EnumValues, ", "), s)})
+			}
+			if field.MaxLength > 0 && utf8.RuneCountInString(s) > field.MaxLength {
+				errs = append(errs, FieldError{Field: name, Reason: fmt.Sprintf("is longer than %d characters", field.MaxLength)})
+			}
+
+		case MetaObject:
+			nested := make(map[string]json.RawMessage)
+			if err := json.Unmarshal(value, &nested); err == nil {
+				errs = append(errs, validateFields(field.Fields, method, nested, name+".")...)
+			}
+
+		case MetaArrayOfObjects:
+			var list []map[string]json.RawMessage
+			if err := json.Unmarshal(value, &list); err == nil {
+				for i, nested := range list {
+					errs = append(errs, validateFields(field.Fields, method, nested, fmt.Sprintf("%s[%d].", name, i))...)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// requiredOn reports whether the field is required on method. AppNexus
+// names one method, or several separated by slashes.
+func requiredOn(field MetaField, method string) bool {
+	for _, m := range strings.Split(field.RequiredOn, "/") {
+		if strings.EqualFold(strings.TrimSpace(m), method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// zeroJSON reports whether value is the encoding of a zero value, which
+// structs without omitempty send for fields the caller did not set
+func zeroJSON(value json.RawMessage) bool {
+	switch string(value) {
+	case "0", `""`, "false", "[]", "{}", `"0"`:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,217 @@
+package appnexus
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fixture, err := os.ReadFile("meta/segment.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metaCalls, posts := 0, 0
+	mux.HandleFunc("/segment/meta", func(w http.ResponseWriter, r *http.Request) {
+		metaCalls++
+		w.Write(fixture)
+	})
+	mux.HandleFunc("/segment/7", func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		fmt.Fprint(w, `{"response":{"status":"OK","id":12}}`)
+	})
+
+	meta, err := client.Segments.Meta()
+	if err != nil {
+		t.Fatalf("Meta returned error: %v", err)
+	}
+	if f := meta.Field("state"); f == nil || !reflect.DeepEqual(f.EnumValues, []string{"active", "inactive"}) {
+		t.Errorf("Meta state field %+v", f)
+	}
+
+	client.Validate = true
+	defer func() { client.Validate = false }()
+
+	_, err = client.Segments.Add(7, &Segment{ID: 3, State: "paused", Code: string(make([]byte, 51))})
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Add returned %v, expected a *ValidationError", err)
+	}
+
+	expected := []FieldError{
+		{Field: "id", Reason: "is read-only"},
+		{Field: "code", Reason: "is longer than 50 characters"},
+		{Field: "state", Reason: `must be one of active, inactive, not "paused"`},
+		{Field: "short_name", Reason: "is required on POST"},
+	}
+	if !reflect.DeepEqual(verr.Fields, expected) {
+		t.Errorf("Add rejected %+v, expected %+v", verr.Fields, expected)
+	}
+	if posts != 0 {
+		t.Errorf("Add sent an invalid segment")
+	}
+
+	// member_id is read-only but only repeats the member the URL names
+	item := &Segment{MemberID: 7, ShortName: "Valid", State: "active"}
+	if _, err := client.Segments.Add(7, item); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	if posts != 1 || item.ID != 12 {
+		t.Errorf("Add sent %d requests and set ID %d", posts, item.ID)
+	}
+
+	// Objects read back carry their id, member and version, which address
+	// them and are accepted
+	read := Segment{ID: 12, MemberID: 7, ShortName: "Valid", LastModified: MustParseTime("2018-01-02 10:00:00")}
+	if _, err := client.Segments.Update(7, read); err != nil {
+		t.Errorf("Update returned error: %v", err)
+	}
+
+	// Other read-only fields are left out of an update, but rejected on a
+	// patch, which only holds what the caller set
+	read.Provider = "acme"
+	if _, err := client.Segments.Update(7, read); err != nil {
+		t.Errorf("Update returned error: %v", err)
+	}
+
+	_, err = client.Segments.Patch(7, 12, SegmentPatch{Provider: String("acme")})
+	if verr, ok := err.(*ValidationError); !ok || verr.Method != "PATCH" || len(verr.Fields) != 1 {
+		t.Errorf("Patch returned %v, expected provider to be read-only", err)
+	}
+	if posts != 3 {
+		t.Errorf("%d requests were sent, expected the add and the two updates", posts)
+	}
+
+	if metaCalls != 1 {
+		t.Errorf("meta fetched %d times, expected once", metaCalls)
+	}
+}
+
+func TestValidate_RoundTrip(t *testing.T) {
+	setup()
+	defer teardown()
+
+	fixture, err := os.ReadFile("meta/segment.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux.HandleFunc("/segment/meta", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(fixture)
+	})
+
+	var body string
+	mux.HandleFunc("/segment/7", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{"response":{"status":"OK","segment":{"id":12,"member_id":7,"short_name":"Old",
+				"provider":"acme","state":"active","last_modified":"2018-01-02 10:00:00"}}}`)
+			return
+		}
+
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+	})
+
+	client.Validate = true
+	defer func() { client.Validate = false }()
+
+	segment, err := client.Segments.Get(7, 12)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+
+	segment.ShortName = "New"
+	if _, err := client.Segments.Update(7, *segment); err != nil {
+		t.Fatalf("Update of the segment read returned error: %v", err)
+	}
+
+	expected := `{"segment":{"active":false,"short_name":"New","state":"active"}}` + "\n"
+	if body != expected {
+		t.Errorf("Update sent %s, expected %s", body, expected)
+	}
+}
+
+func TestValidate_ListServices(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	meta := `{"response":{"status":"OK","fields":[
+        {"name":"id","type":"int","read_only":true},
+        {"name":"name","type":"string","max_length":5,"required_on":"POST/PUT"}]}}`
+	for _, service := range []string{"domain-list", "inventory-list", "payment-rule"} {
+		mux.HandleFunc("/"+service+"/meta", func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, r.URL.Path)
+			fmt.Fprint(w, meta)
+		})
+		mux.HandleFunc("/"+service, func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			fmt.Fprint(w, `{"response":{"status":"OK","id":1}}`)
+		})
+	}
+
+	client.Validate = true
+	defer func() { client.Validate = false }()
+
+	writes := []func() (*Response, error){
+		func() (*Response, error) { return client.DomainLists.Add(&DomainList{Name: "Blocked"}) },
+		func() (*Response, error) { return client.DomainLists.Update(DomainList{ID: 1, Name: "Blocked"}) },
+		func() (*Response, error) { return client.InventoryLists.Add(&InventoryList{Name: "Blocked"}) },
+		func() (*Response, error) { return client.InventoryLists.Update(InventoryList{ID: 1, Name: "Blocked"}) },
+		func() (*Response, error) {
+			return client.PaymentRules.Add(&PaymentRule{PublisherID: 2, Name: "Revshare", PricingType: PricingCPM, CostCPM: MustParseDecimal("1")})
+		},
+		func() (*Response, error) {
+			return client.PaymentRules.Update(PaymentRule{ID: 1, PublisherID: 2, Name: "Revshare", PricingType: PricingCPM, CostCPM: MustParseDecimal("1")})
+		},
+	}
+	for i, write := range writes {
+		var verr *ValidationError
+		if _, err := write(); !errors.As(err, &verr) {
+			t.Errorf("write %d returned %v, expected a *ValidationError", i, err)
+		}
+	}
+
+	expected := []string{"/domain-list/meta", "/inventory-list/meta", "/payment-rule/meta"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("writes sent %v, expected only %v", calls, expected)
+	}
+}
+
+func TestServiceMeta_ValidateNested(t *testing.T) {
+	meta := &ServiceMeta{Fields: []MetaField{
+		{Name: "name", Type: MetaString, RequiredOn: "POST/PUT"},
+		{Name: "buyer", Type: MetaObject, Fields: []MetaField{
+			{Name: "id", Type: MetaInt, RequiredOn: "POST"},
+		}},
+		{Name: "sizes", Type: MetaArrayOfObjects, Fields: []MetaField{
+			{Name: "unit", Type: MetaEnum, EnumValues: []string{"px", "pct"}},
+		}},
+	}}
+
+	deal := map[string]interface{}{
+		"buyer": map[string]interface{}{},
+		"sizes": []map[string]interface{}{{"unit": "px"}, {"unit": "em"}},
+	}
+	fields, err := meta.Validate("PUT", deal)
+	if err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	expected := []FieldError{
+		{Field: "name", Reason: "is required on PUT"},
+		{Field: "sizes[1].unit", Reason: `must be one of px, pct, not "em"`},
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Validate returned %+v, expected %+v", fields, expected)
+	}
+}
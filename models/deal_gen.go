@@ -0,0 +1,103 @@
+// Code generated by apnxgen from meta/deal.json. DO NOT EDIT.
+
+package models
+
+import (
+	"github.com/tnako/appnexus"
+)
+
+// Deal is an object of the deal service.
+type Deal struct {
+	// The ID of the deal. Read-only.
+	ID int64 `json:"id,omitempty" appnexus:"readonly"`
+	// The custom code for the deal.
+	Code string `json:"code,omitempty"`
+	// The name of the deal. Required on POST.
+	Name string `json:"name" appnexus:"required"`
+	// The description of the deal.
+	Description string `json:"description,omitempty"`
+	// Whether the deal is active.
+	Active bool `json:"active"`
+	// The day and time when the deal starts being available.
+	StartDate appnexus.Time `json:"start_date,omitzero"`
+	// The day and time when the deal stops being available.
+	EndDate appnexus.Time `json:"end_date,omitzero"`
+	// The minimum CPM value the buyer must bid.
+	FloorPrice appnexus.Decimal `json:"floor_price,omitzero"`
+	// The currency of the floor price.
+	Currency string `json:"currency,omitempty"`
+	// Whether the deal floor is applied.
+	UseDealFloor bool `json:"use_deal_floor"`
+	// The priority of the deal against other deals.
+	Priority int `json:"priority,omitempty"`
+	// The price of a fixed price deal.
+	AskPrice appnexus.Decimal `json:"ask_price,omitzero"`
+	// Whether the deal's sizes add to or replace the placement sizes.
+	SizePreference DealSizePreference `json:"size_preference,omitempty"`
+	// The version of the deal. Read-only.
+	Version int `json:"version,omitempty" appnexus:"readonly"`
+	// The buyer the deal is offered to. Required on POST.
+	Buyer *DealBuyer `json:"buyer" appnexus:"required"`
+	// The type of the deal.
+	Type *DealType `json:"type,omitempty"`
+	// The auction type of the deal.
+	AuctionType *DealAuctionType `json:"auction_type,omitempty"`
+	// The creative sizes allowed for the deal.
+	Sizes []DealSize `json:"sizes,omitempty"`
+	// Whether the seller or the buyer created the deal. Read-only.
+	CreatedBy DealCreatedBy `json:"created_by,omitempty" appnexus:"readonly"`
+	// When the deal was last modified. Read-only.
+	LastModified appnexus.Time `json:"last_modified,omitzero" appnexus:"readonly"`
+}
+
+// DealBuyer is the buyer of a Deal.
+type DealBuyer struct {
+	// The ID of the buyer.
+	ID int64 `json:"id,omitempty"`
+	// The ID of the bidder of the buyer.
+	BidderID int64 `json:"bidder_id,omitempty"`
+	// The name of the buyer. Read-only.
+	Name string `json:"name,omitempty" appnexus:"readonly"`
+}
+
+// DealType is the type of a Deal.
+type DealType struct {
+	// The ID of the deal type.
+	ID int64 `json:"id,omitempty"`
+	// The name of the deal type. Read-only.
+	Name string `json:"name,omitempty" appnexus:"readonly"`
+}
+
+// DealAuctionType is the auction_type of a Deal.
+type DealAuctionType struct {
+	// The ID of the auction type.
+	ID int64 `json:"id,omitempty"`
+	// The name of the auction type. Read-only.
+	Name string `json:"name,omitempty" appnexus:"readonly"`
+}
+
+// DealSize is one of the sizes of a Deal.
+type DealSize struct {
+	// The width in pixels.
+	Width int `json:"width,omitempty"`
+	// The height in pixels.
+	Height int `json:"height,omitempty"`
+}
+
+// DealSizePreference is a value of the size_preference field.
+type DealSizePreference string
+
+// Values of DealSizePreference
+const (
+	DealSizePreferenceAppend   DealSizePreference = "append"
+	DealSizePreferenceOverride DealSizePreference = "override"
+)
+
+// DealCreatedBy is a value of the created_by field.
+type DealCreatedBy string
+
+// Values of DealCreatedBy
+const (
+	DealCreatedBySeller DealCreatedBy = "seller"
+	DealCreatedByBuyer  DealCreatedBy = "buyer"
+)
@@ -0,0 +1,10 @@
+// Package models holds Go structs for AppNexus objects, generated by
+// cmd/apnxgen from the meta output of each service saved under meta/. They
+// describe every field the API reports, unlike the hand written types of the
+// appnexus package, which model the fields its services use.
+//
+// To add a service, save the response of GET /<service>/meta as
+// meta/<service>.json and run go generate.
+package models
+
+//go:generate go run ../cmd/apnxgen -meta ../meta -out .
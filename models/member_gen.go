@@ -0,0 +1,76 @@
+// Code generated by apnxgen from meta/member.json. DO NOT EDIT.
+
+package models
+
+import (
+	"github.com/tnako/appnexus"
+)
+
+// Member is an object of the member service.
+type Member struct {
+	// The ID of the member. Read-only.
+	ID int64 `json:"id,omitempty" appnexus:"readonly"`
+	// The name of the member.
+	Name string `json:"name,omitempty"`
+	// The short name of the member.
+	ShortName string `json:"short_name,omitempty"`
+	// The state of the member. Read-only.
+	State MemberState `json:"state,omitempty" appnexus:"readonly"`
+	// Whether the member is a direct or a network member. Read-only.
+	EntityType MemberEntityType `json:"entity_type,omitempty" appnexus:"readonly"`
+	// The timezone of the member.
+	Timezone string `json:"timezone,omitempty"`
+	// The default currency of the member.
+	DefaultCurrency string `json:"default_currency,omitempty"`
+	// The contact email address of the member.
+	ContactEmail string `json:"contact_email,omitempty"`
+	// Whether the inventory of the member is exposed for reselling.
+	ResellingExposure MemberResellingExposure `json:"reselling_exposure,omitempty"`
+	// The daily spend limit of the member.
+	DailyBudget appnexus.Decimal `json:"daily_budget,omitzero"`
+	// The revenue share of the member as a seller. Read-only.
+	SellerRevsharePct float64 `json:"seller_revshare_pct,omitempty" appnexus:"readonly"`
+	// The description of the member.
+	Description string `json:"description,omitempty"`
+	// The user owning the account. Read-only.
+	AccountOwnerUser *MemberAccountOwnerUser `json:"account_owner_user,omitempty" appnexus:"readonly"`
+	// When the member was last modified. Read-only.
+	LastModified appnexus.Time `json:"last_modified,omitzero" appnexus:"readonly"`
+}
+
+// MemberAccountOwnerUser is the account_owner_user of a Member.
+type MemberAccountOwnerUser struct {
+	// The ID of the user.
+	ID int64 `json:"id,omitempty"`
+	// The first name of the user.
+	FirstName string `json:"first_name,omitempty"`
+	// The last name of the user.
+	LastName string `json:"last_name,omitempty"`
+}
+
+// MemberState is a value of the state field.
+type MemberState string
+
+// Values of MemberState
+const (
+	MemberStateActive   MemberState = "active"
+	MemberStateInactive MemberState = "inactive"
+)
+
+// MemberEntityType is a value of the entity_type field.
+type MemberEntityType string
+
+// Values of MemberEntityType
+const (
+	MemberEntityTypeDirect  MemberEntityType = "direct"
+	MemberEntityTypeNetwork MemberEntityType = "network"
+)
+
+// MemberResellingExposure is a value of the reselling_exposure field.
+type MemberResellingExposure string
+
+// Values of MemberResellingExposure
+const (
+	MemberResellingExposurePublic  MemberResellingExposure = "public"
+	MemberResellingExposurePrivate MemberResellingExposure = "private"
+)
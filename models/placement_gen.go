@@ -0,0 +1,76 @@
+// Code generated by apnxgen from meta/placement.json. DO NOT EDIT.
+
+package models
+
+import (
+	"github.com/tnako/appnexus"
+)
+
+// Placement is an object of the placement service.
+type Placement struct {
+	// The ID of the placement. Read-only.
+	ID int64 `json:"id,omitempty" appnexus:"readonly"`
+	// A custom code for the placement.
+	Code string `json:"code,omitempty"`
+	// The name of the placement. Required on POST.
+	Name string `json:"name" appnexus:"required"`
+	// The state of the placement.
+	State PlacementState `json:"state,omitempty"`
+	// The ID of the publisher the placement belongs to. Read-only.
+	PublisherID int64 `json:"publisher_id,omitempty" appnexus:"readonly"`
+	// The ID of the site the placement belongs to.
+	SiteID int64 `json:"site_id,omitempty"`
+	// The width of the placement in pixels.
+	Width int `json:"width,omitempty"`
+	// The height of the placement in pixels.
+	Height int `json:"height,omitempty"`
+	// The position of the placement on the page.
+	DefaultPosition PlacementDefaultPosition `json:"default_position,omitempty"`
+	// The minimum price for the placement.
+	ReservePrice appnexus.Decimal `json:"reserve_price,omitzero"`
+	// Whether the referer is hidden from bidders.
+	HideReferer bool `json:"hide_referer"`
+	// The ID of the ad profile applied to the placement.
+	AdProfileID int64 `json:"ad_profile_id,omitempty"`
+	// Additional sizes the placement accepts.
+	SupportedSizes []PlacementSupportedSize `json:"supported_sizes,omitempty"`
+	// The segments users are added to when the placement serves.
+	Segments []PlacementSegment `json:"segments,omitempty"`
+	// When the placement was last modified. Read-only.
+	LastModified appnexus.Time `json:"last_modified,omitzero" appnexus:"readonly"`
+}
+
+// PlacementSupportedSize is one of the supported_sizes of a Placement.
+type PlacementSupportedSize struct {
+	// The width in pixels.
+	Width int `json:"width,omitempty"`
+	// The height in pixels.
+	Height int `json:"height,omitempty"`
+}
+
+// PlacementSegment is one of the segments of a Placement.
+type PlacementSegment struct {
+	// The ID of the segment.
+	ID int64 `json:"id,omitempty"`
+	// The name of the segment. Read-only.
+	Name string `json:"name,omitempty" appnexus:"readonly"`
+}
+
+// PlacementState is a value of the state field.
+type PlacementState string
+
+// Values of PlacementState
+const (
+	PlacementStateActive   PlacementState = "active"
+	PlacementStateInactive PlacementState = "inactive"
+)
+
+// PlacementDefaultPosition is a value of the default_position field.
+type PlacementDefaultPosition string
+
+// Values of PlacementDefaultPosition
+const (
+	PlacementDefaultPositionUnknown PlacementDefaultPosition = "unknown"
+	PlacementDefaultPositionAbove   PlacementDefaultPosition = "above"
+	PlacementDefaultPositionBelow   PlacementDefaultPosition = "below"
+)
@@ -0,0 +1,90 @@
+// Code generated by apnxgen from meta/publisher.json. DO NOT EDIT.
+
+package models
+
+import (
+	"github.com/tnako/appnexus"
+)
+
+// Publisher is an object of the publisher service.
+type Publisher struct {
+	// The ID of the publisher. Read-only.
+	ID int64 `json:"id,omitempty" appnexus:"readonly"`
+	// A custom code for the publisher.
+	Code string `json:"code,omitempty"`
+	// The name of the publisher. Required on POST.
+	Name string `json:"name" appnexus:"required"`
+	// The state of the publisher.
+	State PublisherState `json:"state,omitempty"`
+	// Whether the domains of the publisher are exposed to buyers.
+	ExposeDomains bool `json:"expose_domains"`
+	// Whether the publisher is owned and operated by the member.
+	IsOO bool `json:"is_oo"`
+	// Whether the inventory of the publisher is exposed for reselling. Required
+	// on POST.
+	ResellingExposure PublisherResellingExposure `json:"reselling_exposure" appnexus:"required"`
+	// The ID of the payment rule used when no other applies.
+	BasePaymentRuleID int64 `json:"base_payment_rule_id,omitempty"`
+	// The ID of the base order. Read-only.
+	BaseOrderID int64 `json:"base_order_id,omitempty" appnexus:"readonly"`
+	// The relationship of the member to the inventory.
+	InventoryRelationship PublisherInventoryRelationship `json:"inventory_relationship,omitempty"`
+	// The source of the inventory.
+	InventorySource PublisherInventorySource `json:"inventory_source,omitempty"`
+	// The timezone of the publisher.
+	Timezone string `json:"timezone,omitempty"`
+	// The contact at the publisher.
+	Contact *PublisherContact `json:"contact,omitempty"`
+	// When the publisher was last modified. Read-only.
+	LastModified appnexus.Time `json:"last_modified,omitzero" appnexus:"readonly"`
+}
+
+// PublisherContact is the contact of a Publisher.
+type PublisherContact struct {
+	// The name of the contact.
+	Name string `json:"name,omitempty"`
+	// The phone number of the contact.
+	Phone string `json:"phone,omitempty"`
+	// The email address of the contact.
+	Email string `json:"email,omitempty"`
+}
+
+// PublisherState is a value of the state field.
+type PublisherState string
+
+// Values of PublisherState
+const (
+	PublisherStateActive   PublisherState = "active"
+	PublisherStateInactive PublisherState = "inactive"
+)
+
+// PublisherResellingExposure is a value of the reselling_exposure field.
+type PublisherResellingExposure string
+
+// Values of PublisherResellingExposure
+const (
+	PublisherResellingExposurePublic  PublisherResellingExposure = "public"
+	PublisherResellingExposurePrivate PublisherResellingExposure = "private"
+)
+
+// PublisherInventoryRelationship is a value of the inventory_relationship field.
+type PublisherInventoryRelationship string
+
+// Values of PublisherInventoryRelationship
+const (
+	PublisherInventoryRelationshipUnknown                    PublisherInventoryRelationship = "unknown"
+	PublisherInventoryRelationshipOwnedOperated              PublisherInventoryRelationship = "owned_operated"
+	PublisherInventoryRelationshipDirect                     PublisherInventoryRelationship = "direct"
+	PublisherInventoryRelationshipIndirectSinglePublisher    PublisherInventoryRelationship = "indirect_single_publisher"
+	PublisherInventoryRelationshipIndirectMultiplePublishers PublisherInventoryRelationship = "indirect_multiple_publishers"
+)
+
+// PublisherInventorySource is a value of the inventory_source field.
+type PublisherInventorySource string
+
+// Values of PublisherInventorySource
+const (
+	PublisherInventorySourceRTB      PublisherInventorySource = "rtb"
+	PublisherInventorySourceDirect   PublisherInventorySource = "direct"
+	PublisherInventorySourceIndirect PublisherInventorySource = "indirect"
+)
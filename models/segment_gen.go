@@ -0,0 +1,80 @@
+// Code generated by apnxgen from meta/segment.json. DO NOT EDIT.
+
+package models
+
+import (
+	"github.com/tnako/appnexus"
+)
+
+// Segment is an object of the segment service.
+type Segment struct {
+	// The ID of the segment. Read-only.
+	ID int64 `json:"id,omitempty" appnexus:"readonly"`
+	// Whether the segment can be used.
+	Active bool `json:"active"`
+	// The user-defined code for calling the segment.
+	Code string `json:"code,omitempty"`
+	// The state of the segment.
+	State SegmentState `json:"state,omitempty"`
+	// The short name used to describe the segment. Required on POST.
+	ShortName string `json:"short_name" appnexus:"required"`
+	// Optional description of the segment.
+	Description string `json:"description,omitempty"`
+	// The ID of the member that owns the segment. Read-only.
+	MemberID int64 `json:"member_id,omitempty" appnexus:"readonly"`
+	// Deprecated category of the segment.
+	Category string `json:"category,omitempty"`
+	// Deprecated price of the segment.
+	Price appnexus.Decimal `json:"price,omitzero"`
+	// The number of minutes a user stays in the segment.
+	ExpireMinutes int `json:"expire_minutes,omitempty"`
+	// Whether piggybacking RM pixels is enabled.
+	EnableRMPiggyback bool `json:"enable_rm_piggyback"`
+	// The maximum number of third party user sync pixels to piggyback.
+	MaxUsersyncPixels int `json:"max_usersync_pixels,omitempty"`
+	// The ID of the advertiser using the segment, if it belongs to one.
+	AdvertiserID int64 `json:"advertiser_id,omitempty"`
+	// The name of the data provider. Read-only.
+	Provider string `json:"provider,omitempty" appnexus:"readonly"`
+	// The ID of the parent segment.
+	ParentSegmentID int64 `json:"parent_segment_id,omitempty"`
+	// A query string which adds users to the segment.
+	QuerystringMapping *SegmentQuerystringMapping `json:"querystring_mapping,omitempty"`
+	// When a user was last added to the segment. Read-only.
+	LastActivity appnexus.Time `json:"last_activity,omitzero" appnexus:"readonly"`
+	// When the segment was last modified. Read-only.
+	LastModified appnexus.Time `json:"last_modified,omitzero" appnexus:"readonly"`
+}
+
+// SegmentQuerystringMapping is the querystring_mapping of a Segment.
+type SegmentQuerystringMapping struct {
+	// The query string parameter.
+	Param string `json:"param,omitempty"`
+	// The type of the value.
+	ValueType SegmentQuerystringMappingValueType `json:"value_type,omitempty"`
+	// The values of the parameter.
+	Values []string `json:"values,omitempty"`
+	// Whether an empty value adds users.
+	AllowEmptyText bool `json:"allow_empty_text"`
+	// The publishers the mapping applies to.
+	Publishers []int64 `json:"publishers,omitempty"`
+}
+
+// SegmentState is a value of the state field.
+type SegmentState string
+
+// Values of SegmentState
+const (
+	SegmentStateActive   SegmentState = "active"
+	SegmentStateInactive SegmentState = "inactive"
+)
+
+// SegmentQuerystringMappingValueType is a value of the value_type field.
+type SegmentQuerystringMappingValueType string
+
+// Values of SegmentQuerystringMappingValueType
+const (
+	SegmentQuerystringMappingValueTypeNone    SegmentQuerystringMappingValueType = "none"
+	SegmentQuerystringMappingValueTypeText    SegmentQuerystringMappingValueType = "text"
+	SegmentQuerystringMappingValueTypeNumeric SegmentQuerystringMappingValueType = "numeric"
+)
@@ -0,0 +1,96 @@
+// Code generated by apnxgen from meta/site.json. DO NOT EDIT.
+
+package models
+
+import (
+	"github.com/tnako/appnexus"
+)
+
+// Site is an object of the site service.
+type Site struct {
+	// The ID of the site. Read-only.
+	ID int64 `json:"id,omitempty" appnexus:"readonly"`
+	// A custom code for the site.
+	Code string `json:"code,omitempty"`
+	// The name of the site. Required on POST.
+	Name string `json:"name" appnexus:"required"`
+	// The state of the site.
+	State SiteState `json:"state,omitempty"`
+	// The URL of the site.
+	URL string `json:"url,omitempty"`
+	// The ID of the publisher the site belongs to. Read-only.
+	PublisherID int64 `json:"publisher_id,omitempty" appnexus:"readonly"`
+	// The ID of the primary content category of the site.
+	PrimaryContentCategoryID int64 `json:"primary_content_category_id,omitempty"`
+	// The type of inventory the site supplies. Required on POST.
+	SupplyType SiteSupplyType `json:"supply_type" appnexus:"required"`
+	// Whether the listed creative formats are excluded or included.
+	CreativeFormatAction SiteCreativeFormatAction `json:"creative_format_action,omitempty"`
+	// The intended audience of the site.
+	IntendedAudience SiteIntendedAudience `json:"intended_audience,omitempty"`
+	// The content categories of the site.
+	ContentCategories []SiteContentCategory `json:"content_categories,omitempty"`
+	// The placements of the site. Read-only.
+	Placements []SitePlacement `json:"placements,omitempty" appnexus:"readonly"`
+	// When the site was last modified. Read-only.
+	LastModified appnexus.Time `json:"last_modified,omitzero" appnexus:"readonly"`
+}
+
+// SiteContentCategory is one of the content_categories of a Site.
+type SiteContentCategory struct {
+	// The ID of the content category.
+	ID int64 `json:"id,omitempty"`
+	// The name of the content category. Read-only.
+	Name string `json:"name,omitempty" appnexus:"readonly"`
+	// Whether the category is the primary one.
+	IsPrimary bool `json:"is_primary"`
+}
+
+// SitePlacement is one of the placements of a Site.
+type SitePlacement struct {
+	// The ID of the placement.
+	ID int64 `json:"id,omitempty"`
+	// The code of the placement.
+	Code string `json:"code,omitempty"`
+}
+
+// SiteState is a value of the state field.
+type SiteState string
+
+// Values of SiteState
+const (
+	SiteStateActive   SiteState = "active"
+	SiteStateInactive SiteState = "inactive"
+)
+
+// SiteSupplyType is a value of the supply_type field.
+type SiteSupplyType string
+
+// Values of SiteSupplyType
+const (
+	SiteSupplyTypeWeb             SiteSupplyType = "web"
+	SiteSupplyTypeMobileWeb       SiteSupplyType = "mobile_web"
+	SiteSupplyTypeMobileApp       SiteSupplyType = "mobile_app"
+	SiteSupplyTypeFacebookSidebar SiteSupplyType = "facebook_sidebar"
+	SiteSupplyTypeToolbar         SiteSupplyType = "toolbar"
+)
+
+// SiteCreativeFormatAction is a value of the creative_format_action field.
+type SiteCreativeFormatAction string
+
+// Values of SiteCreativeFormatAction
+const (
+	SiteCreativeFormatActionExclude SiteCreativeFormatAction = "exclude"
+	SiteCreativeFormatActionInclude SiteCreativeFormatAction = "include"
+)
+
+// SiteIntendedAudience is a value of the intended_audience field.
+type SiteIntendedAudience string
+
+// Values of SiteIntendedAudience
+const (
+	SiteIntendedAudienceGeneral    SiteIntendedAudience = "general"
+	SiteIntendedAudienceChildren   SiteIntendedAudience = "children"
+	SiteIntendedAudienceYoungAdult SiteIntendedAudience = "young_adult"
+	SiteIntendedAudienceMature     SiteIntendedAudience = "mature"
+)
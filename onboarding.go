@@ -0,0 +1,131 @@
+package appnexus
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OnboardingSpec describes a whole publisher hierarchy to create in one go
+type OnboardingSpec struct {
+	Publisher Publisher
+
+	// PaymentRule, if set, is created and made the publisher's base
+	// payment rule
+	PaymentRule *PaymentRule
+
+	Sites []SiteSpec
+}
+
+// SiteSpec is a site to create with its placements
+type SiteSpec struct {
+	Site       Site
+	Placements []Placement
+}
+
+// OnboardingResult holds the IDs of every object created by Onboard
+type OnboardingResult struct {
+	PublisherID   int64
+	PaymentRuleID int64
+	SiteIDs       []int64
+	PlacementIDs  []int64
+}
+
+// OnboardingError is returned when a step of Onboard fails. Everything
+// created before the failure has been deleted again, except the objects
+// whose deletion failed as listed in RollbackErrors.
+type OnboardingError struct {
+	Step           string
+	Err            error
+	RollbackErrors []error
+}
+
+func (e *OnboardingError) Error() string {
+	msg := fmt.Sprintf("Onboard: %s: %s", e.Step, e.Err.Error())
+	if len(e.RollbackErrors) == 0 {
+		return msg + " (rolled back)"
+	}
+
+	errs := make([]string, len(e.RollbackErrors))
+	for i, err := range e.RollbackErrors {
+		errs[i] = err.Error()
+	}
+
+	return msg + "; rollback failed: " + strings.Join(errs, "; ")
+}
+
+func (e *OnboardingError) Unwrap() error {
+	return e.Err
+}
+
+// Onboard creates the publisher, its base payment rule, its sites and their
+// placements. If any step fails the objects already created are deleted in
+// strict reverse order of creation, placements before their site and the
+// payment rule before its publisher, and an *OnboardingError is returned
+// alongside the IDs that had been created.
+func (s *PublisherService) Onboard(spec OnboardingSpec) (*OnboardingResult, error) {
+	result := &OnboardingResult{}
+
+	type created struct {
+		name   string
+		delete func() error
+	}
+	var undo []created
+
+	fail := func(step string, err error) (*OnboardingResult, error) {
+		oerr := &OnboardingError{Step: step, Err: err}
+		for i := len(undo) - 1; i >= 0; i-- {
+			if rerr := undo[i].delete(); rerr != nil {
+				oerr.RollbackErrors = append(oerr.RollbackErrors, fmt.Errorf("delete %s: %s", undo[i].name, rerr.Error()))
+			}
+		}
+
+		return result, oerr
+	}
+
+	publisher := spec.Publisher
+	if _, err := s.Add(&publisher); err != nil {
+		return fail("add publisher", err)
+	}
+	pubID := publisher.ID
+	result.PublisherID = pubID
+	undo = append(undo, created{fmt.Sprintf("publisher %d", pubID), func() error { return s.Delete(pubID) }})
+
+	if spec.PaymentRule != nil {
+		rule := *spec.PaymentRule
+		rule.PublisherID = pubID
+		if _, err := s.client.PaymentRules.Add(&rule); err != nil {
+			return fail("add payment rule", err)
+		}
+		result.PaymentRuleID = rule.ID
+		undo = append(undo, created{fmt.Sprintf("payment rule %d", rule.ID), func() error { return s.client.PaymentRules.Delete(rule.ID, pubID) }})
+
+		if err := s.SetBasePaymentRule(pubID, rule.ID); err != nil {
+			return fail("set base payment rule", err)
+		}
+	}
+
+	for _, siteSpec := range spec.Sites {
+		site := siteSpec.Site
+		site.PublisherID = pubID
+		if _, err := s.client.Sites.Add(&site); err != nil {
+			return fail(fmt.Sprintf("add site %q", site.Name), err)
+		}
+		siteID := site.ID
+		result.SiteIDs = append(result.SiteIDs, siteID)
+		undo = append(undo, created{fmt.Sprintf("site %d", siteID), func() error { return s.client.Sites.Delete(siteID, pubID) }})
+
+		for _, p := range siteSpec.Placements {
+			placement := p
+			placement.PublisherID = pubID
+			placement.SiteID = siteID
+			if _, err := s.client.Placements.Add(&placement); err != nil {
+				return fail(fmt.Sprintf("add placement %q", placement.Name), err)
+			}
+			placementID := placement.ID
+			result.PlacementIDs = append(result.PlacementIDs, placementID)
+			undo = append(undo, created{fmt.Sprintf("placement %d", placementID), func() error { return s.client.Placements.Delete(placementID, pubID) }})
+		}
+	}
+
+	return result, nil
+}
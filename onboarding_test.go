@@ -0,0 +1,119 @@
+package appnexus
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestPublisherService_OnboardRollback(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	handler := func(id int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, r.Method+" "+r.URL.RequestURI())
+			if r.Method == "POST" && r.URL.Path == "/placement" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"response":{"status":"OK","id":%d}}`, id)
+		}
+	}
+	mux.HandleFunc("/publisher", handler(2))
+	mux.HandleFunc("/payment-rule", handler(31))
+	mux.HandleFunc("/site", handler(3))
+	mux.HandleFunc("/placement", handler(6))
+
+	spec := OnboardingSpec{
+		Publisher:   Publisher{Name: "Pub"},
+		PaymentRule: &PaymentRule{Name: "Base", PricingType: PricingRevshare, Revshare: MustParseDecimal("0.7")},
+		Sites: []SiteSpec{{
+			Site:       Site{Name: "Site"},
+			Placements: []Placement{{Name: "Leaderboard"}},
+		}},
+	}
+
+	result, err := client.Publishers.Onboard(spec)
+	oerr, ok := err.(*OnboardingError)
+	if !ok || len(oerr.RollbackErrors) != 0 {
+		t.Fatalf("Onboard returned %v, expected a rolled back *OnboardingError", err)
+	}
+
+	expected := []string{
+		"POST /publisher?create_default_placement=false",
+		"POST /payment-rule?publisher_id=2",
+		"PUT /publisher?id=2",
+		"POST /site?publisher_id=2",
+		"POST /placement?publisher_id=2&site_id=3",
+		"DELETE /site?id=3&publisher_id=2",
+		"DELETE /payment-rule?id=31&publisher_id=2",
+		"DELETE /publisher?id=2",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Onboard sent\n%v\nexpected\n%v", calls, expected)
+	}
+
+	if result.PublisherID != 2 || result.PaymentRuleID != 31 || !reflect.DeepEqual(result.SiteIDs, []int64{3}) {
+		t.Errorf("Onboard returned %+v", result)
+	}
+}
+
+func TestPublisherService_OnboardRollbackMiddle(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	sites := 0
+	handler := func(id int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, r.Method+" "+r.URL.RequestURI())
+			if r.Method == "POST" && r.URL.Path == "/site" {
+				sites++
+				if sites == 2 {
+					fmt.Fprint(w, `{"response":{"status":"error","error_id":"SYNTAX","error":"bad site"}}`)
+					return
+				}
+			}
+			fmt.Fprintf(w, `{"response":{"status":"OK","id":%d}}`, id)
+		}
+	}
+	mux.HandleFunc("/publisher", handler(2))
+	mux.HandleFunc("/payment-rule", handler(31))
+	mux.HandleFunc("/site", handler(3))
+	mux.HandleFunc("/placement", handler(6))
+
+	spec := OnboardingSpec{
+		Publisher:   Publisher{Name: "Pub"},
+		PaymentRule: &PaymentRule{Name: "Base", PricingType: PricingRevshare, Revshare: MustParseDecimal("0.7")},
+		Sites: []SiteSpec{
+			{Site: Site{Name: "First"}, Placements: []Placement{{Name: "Leaderboard"}}},
+			{Site: Site{Name: "Second"}, Placements: []Placement{{Name: "Skyscraper"}}},
+		},
+	}
+
+	_, err := client.Publishers.Onboard(spec)
+	oerr, ok := err.(*OnboardingError)
+	if !ok || oerr.Step != `add site "Second"` || len(oerr.RollbackErrors) != 0 {
+		t.Fatalf("Onboard returned %v, expected a rolled back *OnboardingError", err)
+	}
+
+	var deletes []string
+	for _, c := range calls {
+		if c[:6] == "DELETE" {
+			deletes = append(deletes, c)
+		}
+	}
+
+	expected := []string{
+		"DELETE /placement?id=6&publisher_id=2",
+		"DELETE /site?id=3&publisher_id=2",
+		"DELETE /payment-rule?id=31&publisher_id=2",
+		"DELETE /publisher?id=2",
+	}
+	if !reflect.DeepEqual(deletes, expected) {
+		t.Errorf("Onboard rolled back with\n%v\nexpected\n%v", deletes, expected)
+	}
+}
@@ -0,0 +1,40 @@
+package appnexus
+
+import (
+	"encoding/json"
+)
+
+// Bool returns a pointer to v, for use in patch structs
+func Bool(v bool) *bool { return &v }
+
+// Int returns a pointer to v, for use in patch structs
+func Int(v int) *int { return &v }
+
+// Int64 returns a pointer to v, for use in patch structs
+func Int64(v int64) *int64 { return &v }
+
+// Float64 returns a pointer to v, for use in patch structs
+func Float64(v float64) *float64 { return &v }
+
+// String returns a pointer to v, for use in patch structs
+func String(v string) *string { return &v }
+
+// patchBody wraps the set fields of patch under key, adding an explicit null
+// for each json field name listed in nulls
+func patchBody(key string, patch interface{}, nulls []string) (map[string]map[string]json.RawMessage, error) {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for _, name := range nulls {
+		fields[name] = json.RawMessage("null")
+	}
+
+	return map[string]map[string]json.RawMessage{key: fields}, nil
+}
@@ -0,0 +1,93 @@
+package appnexus
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestPatchRequests(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var uri, body string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		uri, body = r.URL.RequestURI(), string(data)
+		fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+	}
+	for _, path := range []string{"/segment/1", "/deal", "/site", "/placement", "/publisher"} {
+		mux.HandleFunc(path, handler)
+	}
+
+	tests := []struct {
+		call func() (*Response, error)
+		uri  string
+		body string
+	}{
+		{
+			func() (*Response, error) {
+				return client.Segments.Patch(1, 4, SegmentPatch{Active: Bool(false), Description: String("")})
+			},
+			"/segment/1?id=4",
+			`{"segment":{"active":false,"description":""}}`,
+		},
+		{
+			func() (*Response, error) {
+				price := MustParseDecimal("1.5")
+				return client.Deals.Patch(7, DealPatch{FloorPrice: &price, Null: []string{"end_date"}})
+			},
+			"/deal?id=7",
+			`{"deal":{"end_date":null,"floor_price":1.5}}`,
+		},
+		{
+			func() (*Response, error) {
+				return client.Sites.Patch(3, 2, SitePatch{URL: String("http://example.com")})
+			},
+			"/site?id=3&publisher_id=2",
+			`{"site":{"url":"http://example.com"}}`,
+		},
+		{
+			func() (*Response, error) {
+				return client.Placements.Patch(6, 2, PlacementPatch{Name: String("Leaderboard"), SiteID: Int64(3)})
+			},
+			"/placement?id=6&publisher_id=2",
+			`{"placement":{"name":"Leaderboard","site_id":3}}`,
+		},
+		{
+			func() (*Response, error) {
+				return client.Placements.Patch(6, 2, PlacementPatch{Width: Int(300), SupportedSizes: &[]Size{{Width: 300, Height: 250}}})
+			},
+			"/placement?id=6&publisher_id=2",
+			`{"placement":{"supported_sizes":[{"width":300,"height":250}],"width":300}}`,
+		},
+		{
+			func() (*Response, error) {
+				return client.Publishers.Patch(2, PublisherPatch{IsOO: Bool(false), Code: String("")})
+			},
+			"/publisher?id=2",
+			`{"publisher":{"code":"","is_oo":false}}`,
+		},
+	}
+
+	for _, test := range tests {
+		uri, body = "", ""
+		if _, err := test.call(); err != nil {
+			t.Errorf("PUT %s returned error: %v", test.uri, err)
+			continue
+		}
+
+		if uri != test.uri || body != test.body+"\n" {
+			t.Errorf("sent PUT %s %s, expected PUT %s %s", uri, body, test.uri, test.body)
+		}
+	}
+}
+
+func TestPatchRequiresID(t *testing.T) {
+	c, _ := NewClient("http://sand.api.appnexus.com/")
+
+	if _, err := c.Deals.Patch(0, DealPatch{Active: Bool(true)}); err == nil {
+		t.Errorf("Deals.Patch accepted a zero ID")
+	}
+}
@@ -0,0 +1,122 @@
+package appnexus
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PaymentRuleService handles all requests to the payment rule service API
+type PaymentRuleService struct {
+	*Response
+	client *Client
+	svc    *Service[PaymentRule]
+}
+
+// PricingType is how a payment rule pays the publisher
+type PricingType string
+
+// Pricing types of a payment rule
+const (
+	PricingRevshare      PricingType = "revshare"
+	PricingCPM           PricingType = "cpm"
+	PricingOwnerRevshare PricingType = "owner_revshare"
+)
+
+// PaymentRule describes how a publisher is paid for its inventory
+type PaymentRule struct {
+	ID                 int64       `json:"id,omitempty"`
+	PublisherID        int64       `json:"publisher_id,omitempty"`
+	Code               string      `json:"code,omitempty"`
+	Name               string      `json:"name"`
+	Description        string      `json:"description,omitempty"`
+	State              string      `json:"state,omitempty"`
+	PricingType        PricingType `json:"pricing_type"`
+	Revshare           Decimal     `json:"revshare,omitzero"`
+	CostCPM            Decimal     `json:"cost_cpm,omitzero"`
+	Priority           int         `json:"priority,omitempty"`
+	ProfileID          int64       `json:"profile_id,omitempty"`
+	DemandFilterAction string      `json:"demand_filter_action,omitempty"`
+	StartDate          Time        `json:"start_date,omitzero"`
+	EndDate            Time        `json:"end_date,omitzero"`
+	LastModified       Time        `json:"last_modified,omitzero"`
+}
+
+// Validate checks that the pricing fields match the pricing type
+func (r *PaymentRule) Validate() error {
+	switch r.PricingType {
+	case PricingRevshare, PricingOwnerRevshare:
+		if r.Revshare.Cmp(Decimal{}) <= 0 || r.Revshare.Cmp(NewDecimal(1, 0)) > 0 {
+			return fmt.Errorf("PaymentRule: %s requires a revshare between 0 and 1, got %s", r.PricingType, r.Revshare)
+		}
+	case PricingCPM:
+		if r.CostCPM.Cmp(Decimal{}) <= 0 {
+			return errors.New("PaymentRule: cpm requires a positive cost_cpm")
+		}
+	default:
+		return fmt.Errorf("PaymentRule: unknown pricing type %q", r.PricingType)
+	}
+
+	if r.Priority != 0 && (r.Priority < 1 || r.Priority > 10) {
+		return fmt.Errorf("PaymentRule: priority must be between 1 and 10, got %d", r.Priority)
+	}
+
+	return nil
+}
+
+func (r *PaymentRule) objectID() *int64     { return &r.ID }
+func (r *PaymentRule) objectVersion() *Time { return &r.LastModified }
+
+// Get a payment rule of a publisher by ID
+func (s *PaymentRuleService) Get(ruleID int64, pubID int64) (*PaymentRule, error) {
+	return s.svc.Get(withID(inPublisher(pubID), ruleID))
+}
+
+// List the payment rules of a publisher
+func (s *PaymentRuleService) List(pubID int64) ([]PaymentRule, *Response, error) {
+	return s.svc.List(inPublisher(pubID), nil)
+}
+
+// Add a new payment rule to item.PublisherID
+func (s *PaymentRuleService) Add(item *PaymentRule) (*Response, error) {
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+
+	return addObject(s.svc, inPublisher(item.PublisherID), item)
+}
+
+// Update an existing payment rule with new data. With Client.SafeUpdates set
+// the update fails with a *ConflictError if the rule was modified since item
+// was read.
+func (s *PaymentRuleService) Update(item PaymentRule) (*Response, error) {
+	if err := item.Validate(); err != nil {
+		return nil, err
+	}
+
+	return updateObject(s.svc, inPublisher(item.PublisherID), item, s.client.SafeUpdates)
+}
+
+// Delete the specified payment rule
+func (s *PaymentRuleService) Delete(ruleID int64, pubID int64) error {
+	return s.svc.deleteObjects(inPublisher(pubID), []int64{ruleID})
+}
+
+// GetBase returns the base payment rule of a publisher
+func (s *PaymentRuleService) GetBase(pubID int64) (*PaymentRule, error) {
+	publisher, err := s.client.Publishers.Get(pubID)
+	if err != nil {
+		return nil, err
+	}
+
+	if publisher.BasePaymentRuleID < 1 {
+		return nil, fmt.Errorf("publisher %d has no base payment rule", pubID)
+	}
+
+	return s.Get(publisher.BasePaymentRuleID, pubID)
+}
+
+// Meta returns the meta of the payment rule service, describing the fields of its
+// objects
+func (s *PaymentRuleService) Meta() (*ServiceMeta, error) {
+	return s.svc.Meta()
+}
@@ -0,0 +1,70 @@
+package appnexus
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestPaymentRule_Validate(t *testing.T) {
+	tests := []struct {
+		rule  PaymentRule
+		valid bool
+	}{
+		{PaymentRule{PricingType: PricingRevshare, Revshare: MustParseDecimal("0.7")}, true},
+		{PaymentRule{PricingType: PricingRevshare, Revshare: MustParseDecimal("1.2")}, false},
+		{PaymentRule{PricingType: PricingOwnerRevshare}, false},
+		{PaymentRule{PricingType: PricingCPM, CostCPM: MustParseDecimal("0.5"), Priority: 5}, true},
+		{PaymentRule{PricingType: PricingCPM, CostCPM: MustParseDecimal("0.5"), Priority: 11}, false},
+		{PaymentRule{PricingType: "flat"}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.rule.Validate(); (err == nil) != test.valid {
+			t.Errorf("Validate(%+v) returned %v", test.rule, err)
+		}
+	}
+}
+
+func TestPaymentRuleService_Add(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var uri, body string
+	mux.HandleFunc("/payment-rule", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		uri, body = r.URL.RequestURI(), string(data)
+		fmt.Fprint(w, `{"response":{"status":"OK","id":31}}`)
+	})
+
+	rule := &PaymentRule{PublisherID: 2, Name: "Base", PricingType: PricingRevshare, Revshare: MustParseDecimal("0.65"), Priority: 5}
+	if _, err := client.PaymentRules.Add(rule); err != nil {
+		t.Fatalf("PaymentRules.Add returned error: %v", err)
+	}
+
+	expected := `{"payment-rule":{"publisher_id":2,"name":"Base","pricing_type":"revshare","revshare":0.65,"priority":5}}` + "\n"
+	if rule.ID != 31 || uri != "/payment-rule?publisher_id=2" || body != expected {
+		t.Errorf("PaymentRules.Add sent %s %s and set ID %d", uri, body, rule.ID)
+	}
+}
+
+func TestPublisherService_SetBasePaymentRule(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var uri, body string
+	mux.HandleFunc("/publisher", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		uri, body = r.URL.RequestURI(), string(data)
+		fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+	})
+
+	if err := client.Publishers.SetBasePaymentRule(2, 31); err != nil {
+		t.Fatalf("Publishers.SetBasePaymentRule returned error: %v", err)
+	}
+
+	if uri != "/publisher?id=2" || body != `{"publisher":{"base_payment_rule_id":31}}`+"\n" {
+		t.Errorf("Publishers.SetBasePaymentRule sent %s %s", uri, body)
+	}
+}
@@ -0,0 +1,150 @@
+package appnexus
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// Hosts serving segment and conversion pixels
+const (
+	PixelSecureHost   = "https://secure.adnxs.com"
+	PixelInsecureHost = "http://ib.adnxs.com"
+)
+
+// gdprMacros are expanded by the ad server with the consent of the user; 32
+// is the AppNexus IAB vendor ID
+const gdprMacros = "gdpr=${GDPR}&gdpr_consent=${GDPR_CONSENT_32}"
+
+// PixelOptions controls the pixel generated for a segment
+type PixelOptions struct {
+	// JS generates a JavaScript pixel (t=1) instead of an image (t=2)
+	JS bool
+
+	// Insecure uses the http host instead of the https one
+	Insecure bool
+
+	// ByCode addresses segments by member and code instead of by ID
+	ByCode bool
+
+	// MemberID is used with ByCode, defaulting to the segment's MemberID
+	MemberID int
+
+	// Value is attached to the added segment, such as a score or price
+	Value string
+
+	// Remove lists segments the user is removed from by the same pixel
+	Remove []Segment
+
+	// Other is passed through as the other parameter
+	Other string
+
+	// GDPR appends the gdpr and gdpr_consent macros
+	GDPR bool
+
+	// ConversionPixelID fires the segment through a conversion pixel
+	// (px?id=) instead of a plain segment pixel
+	ConversionPixelID int64
+}
+
+// PixelURL returns the URL of a pixel adding the user to the segment
+func (s Segment) PixelURL(opt PixelOptions) (string, error) {
+	memberID := opt.MemberID
+	if memberID == 0 {
+		memberID = s.MemberID
+	}
+
+	ref := func(seg Segment) (string, error) {
+		if opt.ByCode {
+			if seg.Code == "" {
+				return "", fmt.Errorf("PixelURL: segment %d has no code", seg.ID)
+			}
+			return url.QueryEscape(seg.Code), nil
+		}
+
+		if seg.ID < 1 {
+			return "", fmt.Errorf("PixelURL: segment %q has no ID", seg.ShortName)
+		}
+		return fmt.Sprintf("%d", seg.ID), nil
+	}
+
+	add, err := ref(s)
+	if err != nil {
+		return "", err
+	}
+
+	if opt.Value != "" {
+		add += ":" + url.QueryEscape(opt.Value)
+	}
+
+	var params []string
+
+	suffix := ""
+	if opt.ByCode {
+		if memberID < 1 {
+			return "", errors.New("PixelURL: segment codes require a member ID")
+		}
+		suffix = "_code"
+	}
+
+	path := "seg"
+	if opt.ConversionPixelID > 0 {
+		path = "px"
+		params = append(params, fmt.Sprintf("id=%d", opt.ConversionPixelID), "seg"+suffix+"="+add)
+	} else {
+		params = append(params, "add"+suffix+"="+add)
+	}
+
+	if len(opt.Remove) > 0 {
+		refs := make([]string, len(opt.Remove))
+		for i, seg := range opt.Remove {
+			if refs[i], err = ref(seg); err != nil {
+				return "", err
+			}
+		}
+		params = append(params, "remove"+suffix+"="+strings.Join(refs, ","))
+	}
+
+	if opt.ByCode {
+		params = append(params, fmt.Sprintf("member=%d", memberID))
+	}
+
+	if opt.Other != "" {
+		params = append(params, "other="+url.QueryEscape(opt.Other))
+	}
+
+	if opt.JS {
+		params = append(params, "t=1")
+	} else {
+		params = append(params, "t=2")
+	}
+
+	if opt.GDPR {
+		params = append(params, gdprMacros)
+	}
+
+	host := PixelSecureHost
+	if opt.Insecure {
+		host = PixelInsecureHost
+	}
+
+	return host + "/" + path + "?" + strings.Join(params, "&"), nil
+}
+
+// PixelTag returns a ready-to-paste HTML snippet for the segment pixel: an
+// img tag, or a script tag when opt.JS is set
+func (s Segment) PixelTag(opt PixelOptions) (string, error) {
+	u, err := s.PixelURL(opt)
+	if err != nil {
+		return "", err
+	}
+
+	comment := fmt.Sprintf("<!-- Segment Pixel - %s - DO NOT MODIFY -->", html.EscapeString(s.ShortName))
+	if opt.JS {
+		return fmt.Sprintf("%s\n<script src=\"%s\" type=\"text/javascript\"></script>\n<!-- End of Segment Pixel -->", comment, html.EscapeString(u)), nil
+	}
+
+	return fmt.Sprintf("%s\n<img src=\"%s\" width=\"1\" height=\"1\" />\n<!-- End of Segment Pixel -->", comment, html.EscapeString(u)), nil
+}
@@ -0,0 +1,53 @@
+package appnexus
+
+import (
+	"testing"
+)
+
+func TestSegment_PixelURL(t *testing.T) {
+	seg := Segment{ID: 123, Code: "auto suv", MemberID: 456, ShortName: "Auto & SUV"}
+
+	tests := []struct {
+		opt      PixelOptions
+		expected string
+	}{
+		{PixelOptions{}, "https://secure.adnxs.com/seg?add=123&t=2"},
+		{PixelOptions{JS: true, Insecure: true}, "http://ib.adnxs.com/seg?add=123&t=1"},
+		{PixelOptions{Value: "7", Remove: []Segment{{ID: 9}, {ID: 10}}}, "https://secure.adnxs.com/seg?add=123:7&remove=9,10&t=2"},
+		{PixelOptions{ByCode: true, Other: "a b"}, "https://secure.adnxs.com/seg?add_code=auto+suv&member=456&other=a+b&t=2"},
+		{PixelOptions{GDPR: true}, "https://secure.adnxs.com/seg?add=123&t=2&gdpr=${GDPR}&gdpr_consent=${GDPR_CONSENT_32}"},
+		{PixelOptions{ConversionPixelID: 77}, "https://secure.adnxs.com/px?id=77&seg=123&t=2"},
+	}
+
+	for _, test := range tests {
+		actual, err := seg.PixelURL(test.opt)
+		if err != nil {
+			t.Errorf("PixelURL(%+v) returned error: %v", test.opt, err)
+			continue
+		}
+
+		if actual != test.expected {
+			t.Errorf("PixelURL(%+v) is %s, expected %s", test.opt, actual, test.expected)
+		}
+	}
+
+	if _, err := (Segment{ID: 1}).PixelURL(PixelOptions{ByCode: true}); err == nil {
+		t.Errorf("PixelURL accepted ByCode without a code")
+	}
+}
+
+func TestSegment_PixelTag(t *testing.T) {
+	seg := Segment{ID: 123, ShortName: "Auto & SUV"}
+
+	actual, err := seg.PixelTag(PixelOptions{Remove: []Segment{{ID: 9}}})
+	if err != nil {
+		t.Fatalf("PixelTag returned error: %v", err)
+	}
+
+	expected := `<!-- Segment Pixel - Auto &amp; SUV - DO NOT MODIFY -->
+<img src="https://secure.adnxs.com/seg?add=123&amp;remove=9&amp;t=2" width="1" height="1" />
+<!-- End of Segment Pixel -->`
+	if actual != expected {
+		t.Errorf("PixelTag returned\n%s\nexpected\n%s", actual, expected)
+	}
+}
@@ -3,136 +3,175 @@ package appnexus
 import (
 	"errors"
 	"fmt"
-	"net/http"
+	"iter"
+	"net/url"
+	"strconv"
 )
 
 // PlacementService handles all requests to the placement service API
 type PlacementService struct {
 	*Response
 	client *Client
+	svc    *Service[Placement]
 }
 
 // Placement is an audience placement within the AppNexus console
 type Placement struct {
-	ID          int64  `json:"id,omitempty"`
-	PublisherID int64  `json:"publisher_id"`
-	SiteID      int64  `json:"site_id,omitempty"`
-	Code        string `json:"code"`
-	State       string `json:"state,omitempty"`
-	Name        string `json:"name"`
-}
-
-type placementResponse struct {
-	*http.Response
-	Obj struct {
-		Placement  `json:"placement,omitempty"`
-		Placements []Placement `json:"placements,omitempty"`
-		Error      string      `json:"error"`
-		Status     string      `json:"status"`
-		Service    string      `json:"service"`
-		Rate       Rate        `json:"dbg_info"`
-	} `json:"response"`
+	ID             int64   `json:"id,omitempty"`
+	PublisherID    int64   `json:"publisher_id"`
+	SiteID         int64   `json:"site_id,omitempty"`
+	Code           string  `json:"code"`
+	State          string  `json:"state,omitempty"`
+	Name           string  `json:"name"`
+	Width          int     `json:"width,omitempty"`
+	Height         int     `json:"height,omitempty"`
+	SupportedSizes []Size  `json:"supported_sizes,omitempty"`
+	ReservePrice   Decimal `json:"reserve_price,omitzero"`
+	LastModified   Time    `json:"last_modified,omitzero"`
 }
 
+// Size is the width and height of a creative slot
+type Size struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func (s Size) String() string {
+	return fmt.Sprintf("%dx%d", s.Width, s.Height)
+}
+
+// PlacementPatch holds the placement fields to change with Patch. Only non-nil fields
+// are sent, so a field is cleared by pointing it at its zero value; fields
+// named in Null are sent as an explicit null.
+type PlacementPatch struct {
+	SiteID         *int64   `json:"site_id,omitempty"`
+	Code           *string  `json:"code,omitempty"`
+	State          *string  `json:"state,omitempty"`
+	Name           *string  `json:"name,omitempty"`
+	Width          *int     `json:"width,omitempty"`
+	Height         *int     `json:"height,omitempty"`
+	SupportedSizes *[]Size  `json:"supported_sizes,omitempty"`
+	ReservePrice   *Decimal `json:"reserve_price,omitempty"`
+
+	Null []string `json:"-"`
+}
+
+func (p *Placement) objectID() *int64     { return &p.ID }
+func (p *Placement) objectVersion() *Time { return &p.LastModified }
+
 // Get a placement from the placement service by ID
 func (s *PlacementService) Get(placementID int64) (*Placement, error) {
-	path := fmt.Sprintf("placement?id=%d", placementID)
-	req, err := s.client.newRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
+	return s.svc.Get(withID(nil, placementID))
+}
 
-	r := &placementResponse{}
-	_, err = s.client.do(req, r)
-	if err != nil {
-		return nil, err
+// GetByCode gets a placement of the publisher by its code, or of any publisher if
+// pubID is zero, as codes are only unique within a publisher. It returns a
+// *NotFoundError if no placement has the code.
+func (s *PlacementService) GetByCode(code string, pubID int64) (*Placement, error) {
+	var scope url.Values
+	if pubID > 0 {
+		scope = inPublisher(pubID)
 	}
 
-	placement := &r.Obj.Placement
-	return placement, nil
+	return getByCode(s.svc, scope, code)
 }
 
 // List available placements from your AppNexus console
 func (s *PlacementService) List(pubID int64) ([]Placement, *Response, error) {
-	path := fmt.Sprintf("placement?publisher_id=%d", pubID)
-	req, err := s.client.newRequest("GET", path, nil)
-	if err != nil {
-		return nil, nil, err
-	}
+	return s.svc.List(inPublisher(pubID), nil)
+}
 
-	placements := &placementResponse{}
-	resp, err := s.client.do(req, placements)
-	if err != nil {
-		return nil, resp, err
-	}
+// ListAll pages through every placement of the publisher
+func (s *PlacementService) ListAll(pubID int64) ([]Placement, error) {
+	return s.svc.ListAll(inPublisher(pubID))
+}
 
-	return placements.Obj.Placements, resp, err
+// All iterates over every placement of the publisher
+func (s *PlacementService) All(pubID int64) iter.Seq2[Placement, error] {
+	return s.svc.All(inPublisher(pubID))
 }
 
-// Add a new placement
+// Add a new placement, to item's site if it has one. The publisher is sent
+// along with a site when known, as the placement names it too.
 func (s *PlacementService) Add(item *Placement) (*Response, error) {
-
-	data := struct {
-		Placement `json:"placement"`
-	}{*item}
-
-	var path string
-
+	scope := inPublisher(item.PublisherID)
 	if item.SiteID > 0 {
-		path = fmt.Sprintf("placement?site_id=%d", item.SiteID)
-	} else {
-		path = fmt.Sprintf("placement?publisher_id=%d", item.PublisherID)
+		scope = url.Values{"site_id": {strconv.FormatInt(item.SiteID, 10)}}
+		if item.PublisherID > 0 {
+			scope.Set("publisher_id", strconv.FormatInt(item.PublisherID, 10))
+		}
 	}
 
-	req, err := s.client.newRequest("POST", path, data)
-
-	if err != nil {
-		return nil, err
-	}
-
-	result := &Response{}
-	resp, err := s.client.do(req, result)
-	if err != nil {
-		return resp, err
-	}
-
-	item.ID, _ = result.Obj.ID.Int64()
-	return result, nil
+	return addObject(s.svc, scope, item)
 }
 
-// Update an existing placement with new data
+// Update an existing placement with new data. With Client.SafeUpdates set the
+// update fails with a *ConflictError if the placement was modified since item was
+// read.
 func (s *PlacementService) Update(item Placement) (*Response, error) {
+	return updateObject(s.svc, inPublisher(item.PublisherID), item, s.client.SafeUpdates)
+}
 
-	data := struct {
-		Placement `json:"placement"`
-	}{item}
+// UpdateWithMerge safely updates the placement, and on conflict calls merge with
+// the current version to reapply the caller's changes before retrying, up to
+// attempts times
+func (s *PlacementService) UpdateWithMerge(item Placement, merge func(current Placement) (Placement, error), attempts int) (*Response, error) {
+	return updateWithMerge(s.svc, inPublisher(item.PublisherID), item, merge, attempts)
+}
 
-	if item.ID < 1 {
-		return nil, errors.New("Update Placement requires a placement to have an ID already")
+// Patch sends only the fields set in patch to the specified placement
+func (s *PlacementService) Patch(placementID int64, pubID int64, patch PlacementPatch) (*Response, error) {
+	if placementID < 1 {
+		return nil, errors.New("Patch Placement requires a placement ID")
 	}
 
-	req, err := s.client.newRequest("PUT", fmt.Sprintf("placement?id=%d&publisher_id=%d", item.ID, item.PublisherID), data)
+	return s.svc.Patch(withID(inPublisher(pubID), placementID), patch, patch.Null)
+}
 
+// UpdateByCode updates the placement with the given code, looking up its ID within
+// item's publisher if it has one
+func (s *PlacementService) UpdateByCode(code string, item Placement) (*Response, error) {
+	current, err := s.GetByCode(code, item.PublisherID)
 	if err != nil {
 		return nil, err
 	}
 
-	result := &Response{}
-	resp, err := s.client.do(req, result)
+	item.ID = current.ID
+	if item.PublisherID == 0 {
+		item.PublisherID = current.PublisherID
+	}
+	return s.Update(item)
+}
+
+// DeleteByCode deletes the placement of the publisher with the given code, or of
+// any publisher if pubID is zero
+func (s *PlacementService) DeleteByCode(code string, pubID int64) error {
+	current, err := s.GetByCode(code, pubID)
 	if err != nil {
-		return resp, err
+		return err
 	}
 
-	return result, nil
+	return s.Delete(current.ID, current.PublisherID)
 }
 
 // Delete the specified placement
 func (s *PlacementService) Delete(placementID int64, pubID int64) error {
-	req, err := s.client.newRequest("DELETE", fmt.Sprintf("placement?id=%d&publisher_id=%d", placementID, pubID), nil)
-	if err != nil {
-		return err
-	}
+	return s.svc.deleteObjects(inPublisher(pubID), []int64{placementID})
+}
+
+// BulkDelete deletes several placements in a single request
+func (s *PlacementService) BulkDelete(placementIDs []int64, pubID int64) error {
+	return s.svc.deleteObjects(inPublisher(pubID), placementIDs)
+}
 
-	_, err = s.client.do(req, nil)
+// Deactivate the specified placement instead of deleting it
+func (s *PlacementService) Deactivate(placementID int64, pubID int64) error {
+	_, err := s.Patch(placementID, pubID, PlacementPatch{State: String("inactive")})
 	return err
 }
+
+// Meta returns the meta of the placement service, describing the fields of its
+// objects
+func (s *PlacementService) Meta() (*ServiceMeta, error) {
+	return s.svc.Meta()
+}
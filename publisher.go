@@ -2,14 +2,15 @@ package appnexus
 
 import (
 	"errors"
-	"fmt"
-	"net/http"
+	"iter"
+	"net/url"
 )
 
 // PublisherService handles all requests to the publisher service API
 type PublisherService struct {
 	*Response
 	client *Client
+	svc    *Service[Publisher]
 }
 
 // Publisher is an audience publisher within the AppNexus console
@@ -23,111 +24,115 @@ type Publisher struct {
 	BasePaymentRuleID     int64  `json:"base_payment_rule_id,omitempty"`
 	InventoryRelationship string `json:"inventory_relationship,omitempty"`
 	InventorySource       string `json:"inventory_source,omitempty"`
+	LastModified          Time   `json:"last_modified,omitzero"`
 }
 
-type publisherResponse struct {
-	*http.Response
-	Obj struct {
-		Publisher  `json:"publisher,omitempty"`
-		Publishers []Publisher `json:"publishers,omitempty"`
-		Error      string      `json:"error"`
-		Status     string      `json:"status"`
-		Service    string      `json:"service"`
-		Rate       Rate        `json:"dbg_info"`
-	} `json:"response"`
+// PublisherPatch holds the publisher fields to change with Patch. Only non-nil fields
+// are sent, so a field is cleared by pointing it at its zero value; fields
+// named in Null are sent as an explicit null.
+type PublisherPatch struct {
+	Code                  *string `json:"code,omitempty"`
+	State                 *string `json:"state,omitempty"`
+	Name                  *string `json:"name,omitempty"`
+	IsOO                  *bool   `json:"is_oo,omitempty"`
+	ResellingExposure     *string `json:"reselling_exposure,omitempty"`
+	BasePaymentRuleID     *int64  `json:"base_payment_rule_id,omitempty"`
+	InventoryRelationship *string `json:"inventory_relationship,omitempty"`
+	InventorySource       *string `json:"inventory_source,omitempty"`
+
+	Null []string `json:"-"`
 }
 
+func (p *Publisher) objectID() *int64     { return &p.ID }
+func (p *Publisher) objectVersion() *Time { return &p.LastModified }
+
 // Get a publisher from the publisher service by ID
 func (s *PublisherService) Get(publisherID int64) (*Publisher, error) {
+	return s.svc.Get(withID(nil, publisherID))
+}
 
-	path := fmt.Sprintf("publisher?id=%d", publisherID)
-	req, err := s.client.newRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	r := &publisherResponse{}
-	_, err = s.client.do(req, r)
-	if err != nil {
-		return nil, err
-	}
-
-	publisher := &r.Obj.Publisher
-	return publisher, nil
+// GetByCode gets a publisher by its code. It returns a *NotFoundError if no
+// publisher has the code.
+func (s *PublisherService) GetByCode(code string) (*Publisher, error) {
+	return getByCode(s.svc, nil, code)
 }
 
 // List available publishers from your AppNexus console
 func (s *PublisherService) List() ([]Publisher, *Response, error) {
-	req, err := s.client.newRequest("GET", "publisher", nil)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	publishers := &publisherResponse{}
-	resp, err := s.client.do(req, publishers)
-	if err != nil {
-		return nil, resp, err
-	}
+	return s.svc.List(nil, nil)
+}
 
-	return publishers.Obj.Publishers, resp, err
+// All iterates over every publisher
+func (s *PublisherService) All() iter.Seq2[Publisher, error] {
+	return s.svc.All(nil)
 }
 
 // Add a new publisher
 func (s *PublisherService) Add(item *Publisher) (*Response, error) {
-
-	data := struct {
-		Publisher `json:"publisher"`
-	}{*item}
-
-	req, err := s.client.newRequest("POST", "publisher?create_default_placement=false", data)
-
-	if err != nil {
-		return nil, err
-	}
-
-	result := &Response{}
-	resp, err := s.client.do(req, result)
-	if err != nil {
-		return resp, err
-	}
-
-	item.ID, _ = result.Obj.ID.Int64()
-	return result, nil
+	return addObject(s.svc, url.Values{"create_default_placement": {"false"}}, item)
 }
 
-// Update an existing publisher with new data
+// Update an existing publisher with new data. With Client.SafeUpdates set the
+// update fails with a *ConflictError if the publisher was modified since item was
+// read.
 func (s *PublisherService) Update(item Publisher) (*Response, error) {
+	return updateObject(s.svc, nil, item, s.client.SafeUpdates)
+}
 
-	data := struct {
-		Publisher `json:"publisher"`
-	}{item}
+// UpdateWithMerge safely updates the publisher, and on conflict calls merge with
+// the current version to reapply the caller's changes before retrying, up to
+// attempts times
+func (s *PublisherService) UpdateWithMerge(item Publisher, merge func(current Publisher) (Publisher, error), attempts int) (*Response, error) {
+	return updateWithMerge(s.svc, nil, item, merge, attempts)
+}
 
-	if item.ID < 1 {
-		return nil, errors.New("Update Publisher requires a publisher to have an ID already")
+// Patch sends only the fields set in patch to the specified publisher
+func (s *PublisherService) Patch(pubID int64, patch PublisherPatch) (*Response, error) {
+	if pubID < 1 {
+		return nil, errors.New("Patch Publisher requires a publisher ID")
 	}
 
-	req, err := s.client.newRequest("PUT", fmt.Sprintf("publisher?id=%d", item.ID), data)
+	return s.svc.Patch(withID(nil, pubID), patch, patch.Null)
+}
 
-	if err != nil {
-		return nil, err
+// SetBasePaymentRule makes ruleID the base payment rule of the publisher
+func (s *PublisherService) SetBasePaymentRule(pubID int64, ruleID int64) error {
+	if ruleID < 1 {
+		return errors.New("SetBasePaymentRule requires a payment rule ID")
 	}
 
-	result := &Response{}
-	resp, err := s.client.do(req, result)
-	if err != nil {
-		return resp, err
-	}
+	_, err := s.Patch(pubID, PublisherPatch{BasePaymentRuleID: Int64(ruleID)})
+	return err
+}
 
-	return result, nil
+// UpdateByCode updates the publisher with the given code, looking up its ID
+func (s *PublisherService) UpdateByCode(code string, item Publisher) (*Response, error) {
+	return updateByCode(s.svc, nil, code, item, s.client.SafeUpdates)
+}
+
+// DeleteByCode deletes the publisher with the given code
+func (s *PublisherService) DeleteByCode(code string) error {
+	return deleteByCode(s.svc, nil, code)
 }
 
 // Delete the specified publisher
 func (s *PublisherService) Delete(pubID int64) error {
-	req, err := s.client.newRequest("DELETE", fmt.Sprintf("publisher?id=%d", pubID), nil)
-	if err != nil {
-		return err
-	}
+	return s.svc.deleteObjects(nil, []int64{pubID})
+}
 
-	_, err = s.client.do(req, nil)
+// BulkDelete deletes several publishers in a single request
+func (s *PublisherService) BulkDelete(pubIDs []int64) error {
+	return s.svc.deleteObjects(nil, pubIDs)
+}
+
+// Deactivate the specified publisher instead of deleting it
+func (s *PublisherService) Deactivate(pubID int64) error {
+	_, err := s.Patch(pubID, PublisherPatch{State: String("inactive")})
 	return err
 }
+
+// Meta returns the meta of the publisher service, describing the fields of its
+// objects
+func (s *PublisherService) Meta() (*ServiceMeta, error) {
+	return s.svc.Meta()
+}
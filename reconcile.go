@@ -0,0 +1,236 @@
+package appnexus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// SegmentAction is the kind of change a SegmentReconciler will make
+type SegmentAction string
+
+// Actions a SegmentPlan may contain
+const (
+	SegmentCreate     SegmentAction = "create"
+	SegmentUpdate     SegmentAction = "update"
+	SegmentDeactivate SegmentAction = "deactivate"
+)
+
+// FieldDiff is a single field which differs between the live and desired
+// version of an object
+type FieldDiff struct {
+	Field string
+	From  interface{}
+	To    interface{}
+}
+
+// SegmentChange is a single planned change, keyed by segment Code
+type SegmentChange struct {
+	Action  SegmentAction
+	Code    string
+	Current *Segment
+	Desired *Segment
+	Fields  []FieldDiff
+}
+
+// SegmentPlan is the set of changes needed to bring a member's segments in
+// line with the desired definitions
+type SegmentPlan struct {
+	MemberID int
+	Changes  []SegmentChange
+}
+
+// SegmentReconciler brings the segments of a member in line with a desired
+// set of definitions keyed by Code. Live segments without a Code are never
+// touched; live segments with a Code missing from the desired set are
+// deactivated.
+type SegmentReconciler struct {
+	client   *Client
+	MemberID int
+
+	// DryRun makes Apply print the plan to Out without sending any writes
+	DryRun bool
+	Out    io.Writer
+}
+
+// NewSegmentReconciler returns a reconciler for the segments of memberID
+func NewSegmentReconciler(c *Client, memberID int) *SegmentReconciler {
+	return &SegmentReconciler{client: c, MemberID: memberID}
+}
+
+// Plan computes the changes needed to turn the live segments into desired
+func (r *SegmentReconciler) Plan(desired []Segment) (*SegmentPlan, error) {
+	wanted := make(map[string]*Segment, len(desired))
+	for i := range desired {
+		seg := &desired[i]
+		if seg.Code == "" {
+			return nil, fmt.Errorf("Plan: desired segment %q has no code", seg.ShortName)
+		}
+		if _, ok := wanted[seg.Code]; ok {
+			return nil, fmt.Errorf("Plan: duplicate desired segment code %q", seg.Code)
+		}
+		wanted[seg.Code] = seg
+	}
+
+	if r.MemberID < 1 {
+		return nil, errors.New("SegmentReconciler requires a MemberID")
+	}
+
+	live, err := r.client.Segments.ListAll(r.MemberID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]*Segment, len(live))
+	for i := range live {
+		if live[i].Code != "" {
+			current[live[i].Code] = &live[i]
+		}
+	}
+
+	plan := &SegmentPlan{MemberID: r.MemberID}
+
+	for _, seg := range desired {
+		want := wanted[seg.Code]
+		have, ok := current[seg.Code]
+		if !ok {
+			plan.Changes = append(plan.Changes, SegmentChange{Action: SegmentCreate, Code: seg.Code, Desired: want})
+			continue
+		}
+
+		if fields := diffSegment(have, want); len(fields) > 0 {
+			plan.Changes = append(plan.Changes, SegmentChange{Action: SegmentUpdate, Code: seg.Code, Current: have, Desired: want, Fields: fields})
+		}
+	}
+
+	codes := make([]string, 0)
+	for code, have := range current {
+		if _, ok := wanted[code]; !ok && have.Active {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		have := current[code]
+		plan.Changes = append(plan.Changes, SegmentChange{
+			Action:  SegmentDeactivate,
+			Code:    code,
+			Current: have,
+			Fields:  []FieldDiff{{Field: "active", From: true, To: false}},
+		})
+	}
+
+	return plan, nil
+}
+
+// Apply the plan using SegmentService Add, Update and Deactivate. In dry-run
+// mode the plan is only printed.
+func (r *SegmentReconciler) Apply(plan *SegmentPlan) error {
+	if r.Out != nil {
+		if err := plan.Print(r.Out); err != nil {
+			return err
+		}
+	}
+
+	if r.DryRun {
+		return nil
+	}
+
+	for _, change := range plan.Changes {
+		var err error
+
+		switch change.Action {
+		case SegmentCreate:
+			seg := *change.Desired
+			seg.MemberID = plan.MemberID
+			_, err = r.client.Segments.Add(plan.MemberID, &seg)
+		case SegmentUpdate:
+			seg := *change.Desired
+			seg.ID = change.Current.ID
+			seg.LastModified = change.Current.LastModified
+			seg.MemberID = plan.MemberID
+			_, err = r.client.Segments.Update(plan.MemberID, seg)
+		case SegmentDeactivate:
+			err = r.client.Segments.Deactivate(plan.MemberID, change.Current.ID)
+		default:
+			err = fmt.Errorf("unknown action %q", change.Action)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Apply: %s segment %q: %s", change.Action, change.Code, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Empty reports whether the plan has no changes
+func (p *SegmentPlan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Print a human-readable diff of the plan
+func (p *SegmentPlan) Print(w io.Writer) error {
+	if p.Empty() {
+		_, err := fmt.Fprintf(w, "member %d: segments are up to date\n", p.MemberID)
+		return err
+	}
+
+	for _, change := range p.Changes {
+		var err error
+
+		switch change.Action {
+		case SegmentCreate:
+			_, err = fmt.Fprintf(w, "+ create %q (%s)\n", change.Code, change.Desired.ShortName)
+		case SegmentUpdate:
+			_, err = fmt.Fprintf(w, "~ update %q [%d]\n", change.Code, change.Current.ID)
+		case SegmentDeactivate:
+			_, err = fmt.Fprintf(w, "- deactivate %q [%d]\n", change.Code, change.Current.ID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if change.Action == SegmentCreate {
+			continue
+		}
+
+		for _, f := range change.Fields {
+			if _, err := fmt.Fprintf(w, "    %s: %#v -> %#v\n", f.Field, f.From, f.To); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *SegmentPlan) String() string {
+	buf := new(bytes.Buffer)
+	_ = p.Print(buf)
+	return buf.String()
+}
+
+// diffSegment lists the managed fields which differ between have and want
+func diffSegment(have, want *Segment) []FieldDiff {
+	var diffs []FieldDiff
+
+	add := func(field string, from, to interface{}) {
+		if from != to {
+			diffs = append(diffs, FieldDiff{Field: field, From: from, To: to})
+		}
+	}
+
+	add("short_name", have.ShortName, want.ShortName)
+	add("description", have.Description, want.Description)
+	add("active", have.Active, want.Active)
+	add("category", have.Category, want.Category)
+	add("expire_minutes", have.ExpireMinutes, want.ExpireMinutes)
+	add("provider", have.Provider, want.Provider)
+	add("parent_segment_id", have.ParentSegmentID, want.ParentSegmentID)
+
+	return diffs
+}
@@ -0,0 +1,85 @@
+package appnexus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestSegmentReconciler(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var writes []string
+	mux.HandleFunc("/segment/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{"response":{"status":"OK","count":4,"segments":[
+                {"id":10,"code":"keep","short_name":"Keep","active":true},
+                {"id":11,"code":"rename","short_name":"Old name","active":true},
+                {"id":12,"code":"gone","short_name":"Gone","active":true},
+                {"id":13,"short_name":"Unmanaged","active":true}]}}`)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		data := struct {
+			Segment Segment `json:"segment"`
+		}{}
+		json.Unmarshal(body, &data)
+		writes = append(writes, fmt.Sprintf("%s %s %s %v", r.Method, r.URL.Query().Get("id"), data.Segment.Code, data.Segment.Active))
+		fmt.Fprint(w, `{"response":{"status":"OK","id":20}}`)
+	})
+
+	desired := []Segment{
+		{Code: "keep", ShortName: "Keep", Active: true},
+		{Code: "rename", ShortName: "New name", Active: true},
+		{Code: "new", ShortName: "New", Active: true},
+	}
+
+	r := NewSegmentReconciler(client, 1)
+	plan, err := r.Plan(desired)
+	if err != nil {
+		t.Fatalf("Plan returned error: %v", err)
+	}
+
+	expected := `~ update "rename" [11]
+    short_name: "Old name" -> "New name"
++ create "new" (New)
+- deactivate "gone" [12]
+    active: true -> false
+`
+	if actual := plan.String(); actual != expected {
+		t.Errorf("Plan printed\n%s\nexpected\n%s", actual, expected)
+	}
+
+	out := new(bytes.Buffer)
+	r.DryRun = true
+	r.Out = out
+	if err := r.Apply(plan); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+	if len(writes) != 0 || out.String() != expected {
+		t.Errorf("dry run sent %v and printed %q", writes, out.String())
+	}
+
+	r.DryRun = false
+	if err := r.Apply(plan); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	expectedWrites := fmt.Sprint([]string{"PUT 11 rename true", "POST  new true", "PUT 12  false"})
+	if actual := fmt.Sprint(writes); actual != expectedWrites {
+		t.Errorf("Apply sent %v, expected %v", actual, expectedWrites)
+	}
+}
+
+func TestSegmentReconciler_PlanDuplicateCode(t *testing.T) {
+	r := NewSegmentReconciler(nil, 1)
+	_, err := r.Plan([]Segment{{Code: "a"}, {Code: "a"}})
+	if err == nil {
+		t.Errorf("Plan accepted duplicate codes")
+	}
+}
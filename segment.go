@@ -2,14 +2,16 @@ package appnexus
 
 import (
 	"errors"
-	"fmt"
-	"net/http"
+	"iter"
+	"net/url"
+	"strconv"
 )
 
 // SegmentService handles all requests to the segment service API
 type SegmentService struct {
 	*Response
 	client *Client
+	svc    *Service[Segment]
 }
 
 // Segment is an audience segment within the AppNexus console
@@ -24,128 +26,137 @@ type Segment struct {
 	Category        string `json:"category,omitempty"`
 	ExpireMinutes   int    `json:"expire_minutes,omitempty"`
 	AdvertiserID    int    `json:"advertiser_id,omitempty"`
-	LastModified    string `json:"last_modified,omitempty"`
+	LastModified    Time   `json:"last_modified,omitzero"`
 	Provider        string `json:"provider,omitempty"`
 	ParentSegmentID int    `json:"parent_segment_id,omitempty"`
 }
 
-type segmentResponse struct {
-	*http.Response
-	Obj struct {
-		Segment  `json:"segment,omitempty"`
-		Segments []Segment `json:"segments,omitempty"`
-		Error    string    `json:"error"`
-		Status   string    `json:"status"`
-		Service  string    `json:"service"`
-		Rate     Rate      `json:"dbg_info"`
-	} `json:"response"`
+// SegmentPatch holds the segment fields to change with Patch. Only non-nil fields
+// are sent, so a field is cleared by pointing it at its zero value; fields
+// named in Null are sent as an explicit null.
+type SegmentPatch struct {
+	Active          *bool   `json:"active,omitempty"`
+	Code            *string `json:"code,omitempty"`
+	State           *string `json:"state,omitempty"`
+	ShortName       *string `json:"short_name,omitempty"`
+	Description     *string `json:"description,omitempty"`
+	Category        *string `json:"category,omitempty"`
+	ExpireMinutes   *int    `json:"expire_minutes,omitempty"`
+	AdvertiserID    *int    `json:"advertiser_id,omitempty"`
+	Provider        *string `json:"provider,omitempty"`
+	ParentSegmentID *int    `json:"parent_segment_id,omitempty"`
+
+	Null []string `json:"-"`
 }
 
-// Get a segment from the segment service by Member ID and Segment ID
-func (s *SegmentService) Get(memberID int, segmentID int) (*Segment, error) {
-
-	path := fmt.Sprintf("segment/%d?id=%d", memberID, segmentID)
-	req, err := s.client.newRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
+// segmentPath moves member_id into the path, as the segment service is
+// addressed per member
+func segmentPath(params url.Values) string {
+	member := params.Get("member_id")
+	params.Del("member_id")
 
-	r := &segmentResponse{}
-	_, err = s.client.do(req, r)
-	if err != nil {
-		return nil, err
-	}
+	return "segment/" + member
+}
 
-	segment := &r.Obj.Segment
-	return segment, nil
+// in selects the segments of the member
+func (s *SegmentService) in(memberID int) url.Values {
+	return url.Values{"member_id": {strconv.Itoa(s.member(memberID))}}
 }
 
-// List available segments from your AppNexus console
-func (s *SegmentService) List(memberID int, opt *ListOptions) ([]Segment, *Response, error) {
-	u, err := addOptions(fmt.Sprintf("segment/%d", memberID), opt)
-	if err != nil {
-		return nil, nil, err
+// member resolves a zero member ID to the client's member
+func (s *SegmentService) member(memberID int) int {
+	if memberID == 0 {
+		return s.client.MemberID
 	}
 
-	req, err := s.client.newRequest("GET", u, opt)
-	if err != nil {
-		return nil, nil, err
-	}
+	return memberID
+}
 
-	segments := &segmentResponse{}
-	resp, err := s.client.do(req, segments)
-	if err != nil {
-		return nil, resp, err
-	}
+func (s *Segment) objectID() *int64     { return &s.ID }
+func (s *Segment) objectVersion() *Time { return &s.LastModified }
 
-	return segments.Obj.Segments, resp, err
+// Get a segment from the segment service by Member ID and Segment ID
+func (s *SegmentService) Get(memberID int, segmentID int) (*Segment, error) {
+	return s.svc.Get(withID(s.in(memberID), int64(segmentID)))
 }
 
-// Add a new segment
-func (s *SegmentService) Add(memberID int, item *Segment) (*Response, error) {
-
-	data := struct {
-		Segment `json:"segment"`
-	}{*item}
+// GetByCode gets a segment of the member by its code. It returns a
+// *NotFoundError if no segment has the code.
+func (s *SegmentService) GetByCode(memberID int, code string) (*Segment, error) {
+	return getByCode(s.svc, s.in(memberID), code)
+}
 
-	req, err := s.client.newRequest("POST", fmt.Sprintf("segment/%d", memberID), data)
+// List available segments from your AppNexus console
+func (s *SegmentService) List(memberID int, opt *ListOptions) ([]Segment, *Response, error) {
+	return s.svc.List(s.in(memberID), opt)
+}
 
-	if err != nil {
-		return nil, err
-	}
+// ListAll pages through every segment of the member
+func (s *SegmentService) ListAll(memberID int) ([]Segment, error) {
+	return s.svc.ListAll(s.in(memberID))
+}
 
-	result := &Response{}
-	resp, err := s.client.do(req, result)
-	if err != nil {
-		return resp, err
-	}
+// All iterates over every segment of the member
+func (s *SegmentService) All(memberID int) iter.Seq2[Segment, error] {
+	return s.svc.All(s.in(memberID))
+}
 
-	item.ID, _ = result.Obj.ID.Int64()
-	return result, nil
+// Add a new segment
+func (s *SegmentService) Add(memberID int, item *Segment) (*Response, error) {
+	return addObject(s.svc, s.in(memberID), item)
 }
 
-// Update an existing segment with new data
+// Update an existing segment with new data. With Client.SafeUpdates set the
+// update fails with a *ConflictError if the segment was modified since item was
+// read.
 func (s *SegmentService) Update(memberID int, item Segment) (*Response, error) {
+	return updateObject(s.svc, s.in(memberID), item, s.client.SafeUpdates)
+}
 
-	data := struct {
-		Segment `json:"segment"`
-	}{item}
+// UpdateWithMerge safely updates the segment, and on conflict calls merge with
+// the current version to reapply the caller's changes before retrying, up to
+// attempts times
+func (s *SegmentService) UpdateWithMerge(memberID int, item Segment, merge func(current Segment) (Segment, error), attempts int) (*Response, error) {
+	return updateWithMerge(s.svc, s.in(memberID), item, merge, attempts)
+}
 
-	if item.ID < 1 {
-		return nil, errors.New("Update Segment requires a segment to have an ID already")
+// Patch sends only the fields set in patch to the specified segment
+func (s *SegmentService) Patch(memberID int, segmentID int64, patch SegmentPatch) (*Response, error) {
+	if segmentID < 1 {
+		return nil, errors.New("Patch Segment requires a segment ID")
 	}
 
-	req, err := s.client.newRequest("PUT", fmt.Sprintf("segment/%d?id=%d", memberID, item.ID), data)
-
-	if err != nil {
-		return nil, err
-	}
+	return s.svc.Patch(withID(s.in(memberID), segmentID), patch, patch.Null)
+}
 
-	result := &Response{}
-	resp, err := s.client.do(req, result)
-	if err != nil {
-		return resp, err
-	}
+// UpdateByCode updates the segment with the given code, looking up its ID
+func (s *SegmentService) UpdateByCode(memberID int, code string, item Segment) (*Response, error) {
+	return updateByCode(s.svc, s.in(memberID), code, item, s.client.SafeUpdates)
+}
 
-	return result, nil
+// DeleteByCode deletes the segment with the given code
+func (s *SegmentService) DeleteByCode(memberID int, code string) error {
+	return deleteByCode(s.svc, s.in(memberID), code)
 }
 
 // Delete the specified segment
-func (s *SegmentService) Delete(memberID int, item Segment) error {
-
-	data := struct {
-		Segment `json:"segment"`
-	}{item}
-
-	if item.ID < 1 {
-		return errors.New("Delete Segment requires a segment to have an ID already")
-	}
+func (s *SegmentService) Delete(memberID int, segmentID int64) error {
+	return s.svc.deleteObjects(s.in(memberID), []int64{segmentID})
+}
 
-	req, err := s.client.newRequest("DELETE", fmt.Sprintf("segment/%d", memberID), data)
-	if err != nil {
-		return err
-	}
+// BulkDelete deletes several segments in a single request
+func (s *SegmentService) BulkDelete(memberID int, segmentIDs []int64) error {
+	return s.svc.deleteObjects(s.in(memberID), segmentIDs)
+}
 
-	_, err = s.client.do(req, nil)
+// Deactivate the specified segment instead of deleting it
+func (s *SegmentService) Deactivate(memberID int, segmentID int64) error {
+	_, err := s.Patch(memberID, segmentID, SegmentPatch{Active: Bool(false)})
 	return err
 }
+
+// Meta returns the meta of the segment service, describing the fields of its
+// objects
+func (s *SegmentService) Meta() (*ServiceMeta, error) {
+	return s.svc.Meta()
+}
@@ -135,17 +135,11 @@ func TestSegmentService_Delete(t *testing.T) {
 	setup()
 	defer teardown()
 
-	data := Segment{
-		ID:        4,
-		ShortName: "Hello Seggy 2",
-		Code:      "seg1",
-	}
-
 	mux.HandleFunc("/segment/1", func(w http.ResponseWriter, r *http.Request) {
 
 	})
 
-	err := client.Segments.Delete(1, data)
+	err := client.Segments.Delete(1, 4)
 	if err != nil {
 		t.Errorf("Segments.Delete returned error: %v", err)
 	}
@@ -0,0 +1,393 @@
+package appnexus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-querystring/query"
+)
+
+// Service is a typed client for an AppNexus service whose objects are T. The
+// package's own services are built on it, and a service the package does not
+// model takes a few lines:
+//
+//	creatives := appnexus.NewService[Creative](client, "creative", "creatives", nil)
+//	creative, err := creatives.Get(url.Values{"id": {"42"}})
+type Service[T any] struct {
+	client   *Client
+	key      string
+	plural   string
+	path     func(params url.Values) string
+	response reflect.Type
+
+	metaMu sync.Mutex
+	meta   *ServiceMeta
+}
+
+// NewService returns a service whose objects are sent and received under key
+// and listed under plural. path builds the request path from the parameters
+// of each call and may move scope parameters out of them into the path; a
+// nil path uses key.
+func NewService[T any](c *Client, key, plural string, path func(params url.Values) string) *Service[T] {
+	// StructOf cannot embed the unexported status type under its own
+	// name, but encoding/json inlines the embedded field all the same
+	obj := reflect.StructOf([]reflect.StructField{
+		{Name: "One", Type: reflect.TypeFor[T](), Tag: reflect.StructTag(`json:"` + key + `"`)},
+		{Name: "List", Type: reflect.TypeFor[[]T](), Tag: reflect.StructTag(`json:"` + plural + `"`)},
+		{Name: "ResponseStatus", Type: statusType, Anonymous: true},
+	})
+	response := reflect.StructOf([]reflect.StructField{
+		{Name: "Obj", Type: obj, Tag: `json:"response"`},
+	})
+
+	return &Service[T]{client: c, key: key, plural: plural, path: path, response: response}
+}
+
+// newResponse returns a pointer to a new struct shaped like the responses of
+// the service, {"response": {key: T, plural: []T}}, so that objects are
+// decoded straight into their type along with the status fields
+func (s *Service[T]) newResponse() reflect.Value {
+	return reflect.New(s.response)
+}
+
+// objects returns the object and the list decoded into a newResponse value
+func (s *Service[T]) objects(r reflect.Value) (T, []T) {
+	obj := r.Elem().Field(0)
+	return obj.Field(0).Interface().(T), obj.Field(1).Interface().([]T)
+}
+
+// request sends a request to the service, building its path from params
+func (s *Service[T]) request(method string, params url.Values, body interface{}, v interface{}) (*Response, error) {
+	return s.requestContext(context.Background(), method, params, body, v)
+}
+
+// requestContext is request with ctx attached to the request, as Call does
+func (s *Service[T]) requestContext(ctx context.Context, method string, params url.Values, body interface{}, v interface{}) (*Response, error) {
+	q := url.Values{}
+	for name, values := range params {
+		q[name] = append([]string(nil), values...)
+	}
+
+	path := s.key
+	if s.path != nil {
+		path = s.path(q)
+	}
+
+	if len(q) > 0 {
+		parts := strings.Split(q.Encode(), "&")
+		for i, part := range parts {
+			// commas separate bulk IDs and need no escaping there, but
+			// are kept escaped in any other parameter
+			if strings.HasPrefix(part, "id=") {
+				parts[i] = strings.ReplaceAll(part, "%2C", ",")
+			}
+		}
+		path += "?" + strings.Join(parts, "&")
+	}
+
+	return s.client.send(ctx, method, path, body, v)
+}
+
+// Meta returns the meta of the service, describing the fields of its
+// objects. It is fetched on first use and kept for the life of the service.
+func (s *Service[T]) Meta() (*ServiceMeta, error) {
+	s.metaMu.Lock()
+	defer s.metaMu.Unlock()
+
+	if s.meta == nil {
+		meta, err := s.client.Meta(s.key)
+		if err != nil {
+			return nil, err
+		}
+		s.meta = meta
+	}
+
+	return s.meta, nil
+}
+
+// writable returns the object v to send in a write to the objects selected
+// by params. With Client.Validate set it is checked against the meta of the
+// service first, returning a *ValidationError for rejected fields, and the
+// object checked is the one sent. An update leaves out the read-only fields,
+// as an object read back carries them, so that a Get, a change and an Update
+// round-trip; an add or a patch, which hold what the caller set, is rejected
+// for them. Without Client.Validate v is sent as is.
+func (s *Service[T]) writable(method string, params url.Values, v interface{}) (interface{}, error) {
+	if !s.client.Validate {
+		return v, nil
+	}
+
+	meta, err := s.Meta()
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := withoutAddress(v, params)
+	if err != nil {
+		return nil, err
+	}
+	if method == "PUT" {
+		withoutReadOnly(meta.Fields, obj)
+	}
+
+	if fields := validateFields(meta.Fields, method, obj, ""); len(fields) > 0 {
+		return nil, &ValidationError{Service: s.key, Method: method, Fields: fields}
+	}
+
+	return obj, nil
+}
+
+// withoutAddress returns the JSON object of v without the read-only fields
+// which only address it rather than change it: last_modified, the version
+// safe updates send back, and fields repeating a parameter of the request,
+// such as the id of an update or the member_id of a segment
+func withoutAddress(v interface{}, params url.Values) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+
+	delete(obj, "last_modified")
+	for name := range params {
+		if value, ok := obj[name]; ok && strings.Trim(string(value), `"`) == params.Get(name) {
+			delete(obj, name)
+		}
+	}
+
+	return obj, nil
+}
+
+// Get returns the object selected by params, such as by id or code. The
+// object is zero if the response held none.
+func (s *Service[T]) Get(params url.Values) (*T, error) {
+	return s.get(context.Background(), params)
+}
+
+// get is Get with ctx attached to the request
+func (s *Service[T]) get(ctx context.Context, params url.Values) (*T, error) {
+	r := s.newResponse()
+	if _, err := s.requestContext(ctx, "GET", params, nil, r.Interface()); err != nil {
+		return nil, err
+	}
+
+	one, _ := s.objects(r)
+	return &one, nil
+}
+
+// List returns one page of the objects selected by params
+func (s *Service[T]) List(params url.Values, opt *ListOptions) ([]T, *Response, error) {
+	return s.list(context.Background(), params, opt)
+}
+
+// list is List with ctx attached to the request
+func (s *Service[T]) list(ctx context.Context, params url.Values, opt *ListOptions) ([]T, *Response, error) {
+	if opt != nil {
+		values, err := query.Values(opt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		merged := url.Values{}
+		for name, v := range params {
+			merged[name] = v
+		}
+		for name, v := range values {
+			merged[name] = v
+		}
+		params = merged
+	}
+
+	r := s.newResponse()
+	resp, err := s.requestContext(ctx, "GET", params, nil, r.Interface())
+	if err != nil {
+		return nil, resp, err
+	}
+
+	_, list := s.objects(r)
+	return list, resp, nil
+}
+
+// All iterates over every object selected by params, paging through the
+// service. Iteration stops after the first error.
+func (s *Service[T]) All(params url.Values) iter.Seq2[T, error] {
+	return s.all(context.Background(), params)
+}
+
+// all is All with ctx attached to each request
+func (s *Service[T]) all(ctx context.Context, params url.Values) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		opt := &ListOptions{NumElements: 100}
+
+		for {
+			items, resp, err := s.list(ctx, params, opt)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			opt.StartElement += len(items)
+			if len(items) == 0 || resp == nil || opt.StartElement >= resp.Obj.Count {
+				return
+			}
+		}
+	}
+}
+
+// ListAll returns every object selected by params
+func (s *Service[T]) ListAll(params url.Values) ([]T, error) {
+	return s.listAll(context.Background(), params)
+}
+
+// listAll is ListAll with ctx attached to each request
+func (s *Service[T]) listAll(ctx context.Context, params url.Values) ([]T, error) {
+	var all []T
+	for item, err := range s.all(ctx, params) {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, item)
+	}
+
+	return all, nil
+}
+
+// Add creates the object and returns its new ID. With Client.Validate set it
+// is checked against the meta first.
+func (s *Service[T]) Add(params url.Values, item T) (int64, *Response, error) {
+	obj, err := s.writable("POST", params, item)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result := &Response{}
+	resp, err := s.request("POST", params, map[string]interface{}{s.key: obj}, result)
+	if err != nil {
+		return 0, resp, err
+	}
+
+	id, _ := result.Obj.ID.Int64()
+	return id, result, nil
+}
+
+// Update replaces the object selected by params with item. With
+// Client.Validate set it is checked against the meta first, leaving out its
+// read-only fields.
+func (s *Service[T]) Update(params url.Values, item T) (*Response, error) {
+	obj, err := s.writable("PUT", params, item)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Response{}
+	resp, err := s.request("PUT", params, map[string]interface{}{s.key: obj}, result)
+	if err != nil {
+		return resp, err
+	}
+
+	return result, nil
+}
+
+// Patch sends only the set fields of patch to the object selected by params,
+// with an explicit null for each json field name in nulls
+func (s *Service[T]) Patch(params url.Values, patch interface{}, nulls []string) (*Response, error) {
+	data, err := patchBody(s.key, patch, nulls)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := s.writable("PATCH", params, data[s.key])
+	if err != nil {
+		return nil, err
+	}
+	data[s.key] = obj.(map[string]json.RawMessage)
+
+	result := &Response{}
+	resp, err := s.request("PUT", params, data, result)
+	if err != nil {
+		return resp, err
+	}
+
+	return result, nil
+}
+
+// Delete deletes the objects selected by params
+func (s *Service[T]) Delete(params url.Values) error {
+	if params.Get("id") == "" {
+		return errors.New("Delete requires an id")
+	}
+
+	_, err := s.request("DELETE", params, nil, nil)
+	return err
+}
+
+// object constrains the types of the package's services to those whose
+// pointer gives access to the ID and last_modified, which adds, safe updates,
+// lookups by code and deletes by ID rely on
+type object[T any] interface {
+	*T
+	objectID() *int64
+	objectVersion() *Time
+}
+
+// name is the type of the service's objects, such as Segment, for errors
+func (s *Service[T]) name() string {
+	return reflect.TypeFor[T]().Name()
+}
+
+// withID copies scope, the parameters selecting the objects of a member or
+// publisher, and adds the comma separated ids
+func withID(scope url.Values, ids ...int64) url.Values {
+	q := url.Values{"id": {idList(ids)}}
+	for name, values := range scope {
+		q[name] = values
+	}
+
+	return q
+}
+
+// addObject creates item within scope and sets its new ID
+func addObject[T any, P object[T]](s *Service[T], scope url.Values, item *T) (*Response, error) {
+	id, resp, err := s.Add(scope, *item)
+	if err != nil {
+		return resp, err
+	}
+
+	*P(item).objectID() = id
+	return resp, nil
+}
+
+// deleteObjects deletes the objects of scope with the given IDs in a single
+// request
+func (s *Service[T]) deleteObjects(scope url.Values, ids []int64) error {
+	if len(ids) == 0 || slices.ContainsFunc(ids, func(id int64) bool { return id < 1 }) {
+		return fmt.Errorf("Delete %s requires at least one ID, all positive", s.name())
+	}
+
+	return s.Delete(withID(scope, ids...))
+}
+
+// inPublisher scopes a call to the objects of a publisher
+func inPublisher(pubID int64) url.Values {
+	return url.Values{"publisher_id": {strconv.FormatInt(pubID, 10)}}
+}
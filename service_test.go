@@ -0,0 +1,106 @@
+package appnexus
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type testCreative struct {
+	ID    int64  `json:"id,omitempty"`
+	Name  string `json:"name"`
+	State string `json:"state,omitempty"`
+}
+
+func TestService(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var calls []string
+	mux.HandleFunc("/creative", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		calls = append(calls, fmt.Sprintf("%s %s %s", r.Method, r.URL.RequestURI(), body))
+
+		switch {
+		case r.Method == "POST":
+			fmt.Fprint(w, `{"response":{"status":"OK","id":12}}`)
+		case r.URL.Query().Get("id") == "12":
+			fmt.Fprint(w, `{"response":{"status":"OK","creative":{"id":12,"name":"Banner"}}}`)
+		case r.URL.Query().Get("start_element") == "":
+			fmt.Fprint(w, `{"response":{"status":"OK","count":3,"creatives":[{"id":1},{"id":2}]}}`)
+		default:
+			fmt.Fprint(w, `{"response":{"status":"OK","count":3,"creatives":[{"id":3}]}}`)
+		}
+	})
+
+	creatives := NewService[testCreative](client, "creative", "creatives", nil)
+
+	id, _, err := creatives.Add(nil, testCreative{Name: "Banner"})
+	if err != nil || id != 12 {
+		t.Fatalf("Add returned %d, %v", id, err)
+	}
+
+	creative, err := creatives.Get(url.Values{"id": {"12"}})
+	if err != nil || creative.Name != "Banner" {
+		t.Fatalf("Get returned %+v, %v", creative, err)
+	}
+
+	var ids []int64
+	for c, err := range creatives.All(url.Values{"advertiser_id": {"5"}}) {
+		if err != nil {
+			t.Fatalf("All returned error: %v", err)
+		}
+		ids = append(ids, c.ID)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2, 3}) {
+		t.Errorf("All returned IDs %v", ids)
+	}
+
+	if _, err := creatives.Update(url.Values{"id": {"12"}}, testCreative{ID: 12, Name: "Skyscraper"}); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+
+	if err := creatives.Delete(url.Values{"id": {"12,13"}}); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+
+	expected := []string{
+		`POST /creative {"creative":{"name":"Banner"}}` + "\n",
+		"GET /creative?id=12 ",
+		"GET /creative?advertiser_id=5&num_elements=100 ",
+		"GET /creative?advertiser_id=5&num_elements=100&start_element=2 ",
+		`PUT /creative?id=12 {"creative":{"id":12,"name":"Skyscraper"}}` + "\n",
+		"DELETE /creative?id=12,13 ",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Service sent\n%q\nexpected\n%q", calls, expected)
+	}
+
+	if err := creatives.Delete(nil); err == nil {
+		t.Error("Delete without an id returned no error")
+	}
+}
+
+func TestService_QueryCommas(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var uri string
+	mux.HandleFunc("/creative", func(w http.ResponseWriter, r *http.Request) {
+		uri = r.URL.RequestURI()
+		fmt.Fprint(w, `{"response":{"status":"OK"}}`)
+	})
+
+	creatives := NewService[testCreative](client, "creative", "creatives", nil)
+	params := url.Values{"code": {"a,b"}, "search": {"x,y"}}
+	if _, err := creatives.request("DELETE", withID(params, 1, 2), nil, nil); err != nil {
+		t.Fatalf("request returned error: %v", err)
+	}
+
+	if expected := "/creative?code=a%2Cb&id=1,2&search=x%2Cy"; uri != expected {
+		t.Errorf("request sent %s, expected %s", uri, expected)
+	}
+}
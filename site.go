@@ -2,134 +2,154 @@ package appnexus
 
 import (
 	"errors"
-	"fmt"
-	"net/http"
+	"iter"
+	"net/url"
 )
 
 // SiteService handles all requests to the site service API
 type SiteService struct {
 	*Response
 	client *Client
+	svc    *Service[Site]
 }
 
 // Site is an audience site within the AppNexus console
 type Site struct {
-	ID          int64  `json:"id,omitempty"`
-	PublisherID int64  `json:"publisher_id"`
-	Code        string `json:"code,omitempty"`
-	State       string `json:"state,omitempty"`
-	Name        string `json:"name"`
-	URL         string `json:"url,omitempty"`
-	SupplyType  string `json:"supply_type"`
-}
-
-type siteResponse struct {
-	*http.Response
-	Obj struct {
-		Site    `json:"site,omitempty"`
-		Sites   []Site `json:"sites,omitempty"`
-		Error   string `json:"error"`
-		Status  string `json:"status"`
-		Service string `json:"service"`
-		Rate    Rate   `json:"dbg_info"`
-	} `json:"response"`
+	ID           int64  `json:"id,omitempty"`
+	PublisherID  int64  `json:"publisher_id"`
+	Code         string `json:"code,omitempty"`
+	State        string `json:"state,omitempty"`
+	Name         string `json:"name"`
+	URL          string `json:"url,omitempty"`
+	SupplyType   string `json:"supply_type"`
+	LastModified Time   `json:"last_modified,omitzero"`
 }
 
+// SitePatch holds the site fields to change with Patch. Only non-nil fields
+// are sent, so a field is cleared by pointing it at its zero value; fields
+// named in Null are sent as an explicit null.
+type SitePatch struct {
+	Code       *string `json:"code,omitempty"`
+	State      *string `json:"state,omitempty"`
+	Name       *string `json:"name,omitempty"`
+	URL        *string `json:"url,omitempty"`
+	SupplyType *string `json:"supply_type,omitempty"`
+
+	Null []string `json:"-"`
+}
+
+func (s *Site) objectID() *int64     { return &s.ID }
+func (s *Site) objectVersion() *Time { return &s.LastModified }
+
 // Get a site from the site service by ID
 func (s *SiteService) Get(params ...int64) (*Site, error) {
-	var path string
+	q := withID(nil, params[0])
 	if len(params) > 1 {
-		path = fmt.Sprintf("site?id=%d&publisher_id=%d", params[0], params[1])
-	} else {
-		path = fmt.Sprintf("site?id=%d", params[0])
-	}
-	req, err := s.client.newRequest("GET", path, nil)
-	if err != nil {
-		return nil, err
+		q = withID(inPublisher(params[1]), params[0])
 	}
 
-	r := &siteResponse{}
-	_, err = s.client.do(req, r)
-	if err != nil {
-		return nil, err
+	return s.svc.Get(q)
+}
+
+// GetByCode gets a site of the publisher by its code, or of any publisher if
+// pubID is zero, as codes are only unique within a publisher. It returns a
+// *NotFoundError if no site has the code.
+func (s *SiteService) GetByCode(code string, pubID int64) (*Site, error) {
+	var scope url.Values
+	if pubID > 0 {
+		scope = inPublisher(pubID)
 	}
 
-	site := &r.Obj.Site
-	return site, nil
+	return getByCode(s.svc, scope, code)
 }
 
 // List available sites from your AppNexus console
 func (s *SiteService) List() ([]Site, *Response, error) {
-	req, err := s.client.newRequest("GET", "site", nil)
-	if err != nil {
-		return nil, nil, err
-	}
+	return s.svc.List(nil, nil)
+}
 
-	sites := &siteResponse{}
-	resp, err := s.client.do(req, sites)
-	if err != nil {
-		return nil, resp, err
-	}
+// ListAll pages through every site of the publisher
+func (s *SiteService) ListAll(pubID int64) ([]Site, error) {
+	return s.svc.ListAll(inPublisher(pubID))
+}
 
-	return sites.Obj.Sites, resp, err
+// All iterates over every site of the publisher
+func (s *SiteService) All(pubID int64) iter.Seq2[Site, error] {
+	return s.svc.All(inPublisher(pubID))
 }
 
 // Add a new site
 func (s *SiteService) Add(item *Site) (*Response, error) {
-
-	data := struct {
-		Site `json:"site"`
-	}{*item}
-
-	req, err := s.client.newRequest("POST", fmt.Sprintf("site?publisher_id=%d", item.PublisherID), data)
-
-	if err != nil {
-		return nil, err
-	}
-
-	result := &Response{}
-	resp, err := s.client.do(req, result)
-	if err != nil {
-		return resp, err
-	}
-
-	item.ID, _ = result.Obj.ID.Int64()
-	return result, nil
+	return addObject(s.svc, inPublisher(item.PublisherID), item)
 }
 
-// Update an existing site with new data
+// Update an existing site with new data. With Client.SafeUpdates set the
+// update fails with a *ConflictError if the site was modified since item was
+// read.
 func (s *SiteService) Update(item Site) (*Response, error) {
+	return updateObject(s.svc, inPublisher(item.PublisherID), item, s.client.SafeUpdates)
+}
 
-	data := struct {
-		Site `json:"site"`
-	}{item}
+// UpdateWithMerge safely updates the site, and on conflict calls merge with
+// the current version to reapply the caller's changes before retrying, up to
+// attempts times
+func (s *SiteService) UpdateWithMerge(item Site, merge func(current Site) (Site, error), attempts int) (*Response, error) {
+	return updateWithMerge(s.svc, inPublisher(item.PublisherID), item, merge, attempts)
+}
 
-	if item.ID < 1 {
-		return nil, errors.New("Update Site requires a site to have an ID already")
+// Patch sends only the fields set in patch to the specified site
+func (s *SiteService) Patch(siteID int64, pubID int64, patch SitePatch) (*Response, error) {
+	if siteID < 1 {
+		return nil, errors.New("Patch Site requires a site ID")
 	}
 
-	req, err := s.client.newRequest("PUT", fmt.Sprintf("site?id=%d&publisher_id=%d", item.ID, item.PublisherID), data)
+	return s.svc.Patch(withID(inPublisher(pubID), siteID), patch, patch.Null)
+}
 
+// UpdateByCode updates the site with the given code, looking up its ID within
+// item's publisher if it has one
+func (s *SiteService) UpdateByCode(code string, item Site) (*Response, error) {
+	current, err := s.GetByCode(code, item.PublisherID)
 	if err != nil {
 		return nil, err
 	}
 
-	result := &Response{}
-	resp, err := s.client.do(req, result)
+	item.ID = current.ID
+	if item.PublisherID == 0 {
+		item.PublisherID = current.PublisherID
+	}
+	return s.Update(item)
+}
+
+// DeleteByCode deletes the site of the publisher with the given code, or of
+// any publisher if pubID is zero
+func (s *SiteService) DeleteByCode(code string, pubID int64) error {
+	current, err := s.GetByCode(code, pubID)
 	if err != nil {
-		return resp, err
+		return err
 	}
 
-	return result, nil
+	return s.Delete(current.ID, current.PublisherID)
 }
 
 // Delete the specified site
 func (s *SiteService) Delete(siteID int64, pubID int64) error {
-	req, err := s.client.newRequest("DELETE", fmt.Sprintf("site?id=%d&publisher_id=%d", siteID, pubID), nil)
-	if err != nil {
-		return err
-	}
+	return s.svc.deleteObjects(inPublisher(pubID), []int64{siteID})
+}
+
+// BulkDelete deletes several sites in a single request
+func (s *SiteService) BulkDelete(siteIDs []int64, pubID int64) error {
+	return s.svc.deleteObjects(inPublisher(pubID), siteIDs)
+}
 
-	_, err = s.client.do(req, nil)
+// Deactivate the specified site instead of deleting it
+func (s *SiteService) Deactivate(siteID int64, pubID int64) error {
+	_, err := s.Patch(siteID, pubID, SitePatch{State: String("inactive")})
 	return err
 }
+
+// Meta returns the meta of the site service, describing the fields of its
+// objects
+func (s *SiteService) Meta() (*ServiceMeta, error) {
+	return s.svc.Meta()
+}
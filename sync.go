@@ -0,0 +1,427 @@
+package appnexus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Services supported by the Syncer
+const (
+	SyncSegments   = "segment"
+	SyncDeals      = "deal"
+	SyncSites      = "site"
+	SyncPlacements = "placement"
+	SyncPublishers = "publisher"
+)
+
+// SyncEventType describes what happened to an object since the last sync
+type SyncEventType string
+
+// Event types emitted by the Syncer
+const (
+	SyncCreated SyncEventType = "created"
+	SyncUpdated SyncEventType = "updated"
+	SyncDeleted SyncEventType = "deleted"
+)
+
+// SyncEvent is a single change passed to the Syncer callback. Object holds a
+// *Segment, *Deal, *Site, *Placement or *Publisher depending on Service, and
+// is nil for deleted objects.
+type SyncEvent struct {
+	Service      string
+	Type         SyncEventType
+	ID           int64
+	LastModified time.Time
+	Object       interface{}
+}
+
+// SyncState is what the Syncer persists between runs for a single service.
+// AtWatermark lists the objects already emitted with a last_modified equal to
+// the watermark, which the next run fetches again and skips.
+type SyncState struct {
+	Watermark   time.Time      `json:"watermark"`
+	AtWatermark []int64        `json:"at_watermark,omitempty"`
+	Known       map[int64]bool `json:"known,omitempty"`
+}
+
+// SyncStore persists the sync state of each service between runs
+type SyncStore interface {
+	Load(service string) (*SyncState, error)
+	Save(service string, state *SyncState) error
+}
+
+// MemorySyncStore keeps sync state in memory only
+type MemorySyncStore struct {
+	mu     sync.Mutex
+	states map[string]SyncState
+}
+
+// Load the state for a service, returning an empty state if none was saved
+func (m *MemorySyncStore) Load(service string) (*SyncState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.states[service]
+	return state.clone(), nil
+}
+
+// Save the state for a service
+func (m *MemorySyncStore) Save(service string, state *SyncState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.states == nil {
+		m.states = make(map[string]SyncState)
+	}
+
+	m.states[service] = *state.clone()
+	return nil
+}
+
+// FileSyncStore keeps sync state for all services in a single JSON file
+type FileSyncStore struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Load the state for a service, returning an empty state if none was saved
+func (f *FileSyncStore) Load(service string) (*SyncState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	states, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+
+	state := states[service]
+	return state.clone(), nil
+}
+
+// Save the state for a service
+func (f *FileSyncStore) Save(service string, state *SyncState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	states, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	states[service] = *state
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := f.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, f.Path)
+}
+
+func (f *FileSyncStore) read() (map[string]SyncState, error) {
+	states := make(map[string]SyncState)
+
+	data, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &states); err != nil {
+			return nil, errors.New("FileSyncStore: " + err.Error())
+		}
+	}
+
+	return states, nil
+}
+
+func (s SyncState) clone() *SyncState {
+	c := &SyncState{
+		Watermark:   s.Watermark,
+		AtWatermark: append([]int64(nil), s.AtWatermark...),
+		Known:       make(map[int64]bool, len(s.Known)),
+	}
+	for id := range s.Known {
+		c.Known[id] = true
+	}
+
+	return c
+}
+
+// Syncer keeps a local mirror current by fetching objects modified since a
+// stored watermark and emitting created/updated/deleted events
+type Syncer struct {
+	client *Client
+	Store  SyncStore
+
+	// MemberID is used for member scoped services such as segments. It
+	// defaults to the client's MemberID.
+	MemberID int
+
+	// Location is the timezone last_modified values are reported in
+	Location *time.Location
+
+	// DetectDeletes lists the IDs of every object on each run so that objects
+	// which have disappeared since the last run are reported as deleted. It
+	// also makes the Syncer keep the IDs it has seen, which tells created
+	// objects from updated ones; without it every change is SyncUpdated and
+	// no IDs are stored.
+	DetectDeletes bool
+}
+
+type syncSource struct {
+	key    string
+	path   func(s *Syncer) string
+	decode func(data []byte) (interface{}, error)
+}
+
+var syncSources = map[string]syncSource{
+	SyncSegments: {
+		key:  "segments",
+		path: func(s *Syncer) string { return fmt.Sprintf("segment/%d", s.memberID()) },
+		decode: func(data []byte) (interface{}, error) {
+			v := &Segment{}
+			return v, json.Unmarshal(data, v)
+		},
+	},
+	SyncDeals: {
+		key:  "deals",
+		path: func(s *Syncer) string { return "deal" },
+		decode: func(data []byte) (interface{}, error) {
+			v := &Deal{}
+			return v, json.Unmarshal(data, v)
+		},
+	},
+	SyncSites: {
+		key:  "sites",
+		path: func(s *Syncer) string { return "site" },
+		decode: func(data []byte) (interface{}, error) {
+			v := &Site{}
+			return v, json.Unmarshal(data, v)
+		},
+	},
+	SyncPlacements: {
+		key:  "placements",
+		path: func(s *Syncer) string { return "placement" },
+		decode: func(data []byte) (interface{}, error) {
+			v := &Placement{}
+			return v, json.Unmarshal(data, v)
+		},
+	},
+	SyncPublishers: {
+		key:  "publishers",
+		path: func(s *Syncer) string { return "publisher" },
+		decode: func(data []byte) (interface{}, error) {
+			v := &Publisher{}
+			return v, json.Unmarshal(data, v)
+		},
+	},
+}
+
+// NewSyncer returns a Syncer for the client which persists state in store
+func NewSyncer(c *Client, store SyncStore) *Syncer {
+	return &Syncer{
+		client:   c,
+		Store:    store,
+		Location: time.UTC,
+	}
+}
+
+// SyncAll syncs every supported service in turn
+func (s *Syncer) SyncAll(fn func(SyncEvent) error) error {
+	services := make([]string, 0, len(syncSources))
+	for name := range syncSources {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+
+	for _, name := range services {
+		if err := s.Sync(name, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Sync fetches every object of the service modified since the stored
+// watermark and passes an event for each to fn. The new watermark is only
+// persisted once all events have been handled without error, so fn may see
+// the same change again after a failure and should be idempotent.
+func (s *Syncer) Sync(service string, fn func(SyncEvent) error) error {
+	src, ok := syncSources[service]
+	if !ok {
+		return fmt.Errorf("Sync: unsupported service %q", service)
+	}
+
+	if s.Store == nil {
+		return errors.New("Sync requires a SyncStore")
+	}
+
+	state, err := s.Store.Load(service)
+	if err != nil {
+		return err
+	}
+	if !s.DetectDeletes {
+		state.Known = nil
+	} else if state.Known == nil {
+		state.Known = make(map[int64]bool)
+	}
+
+	// min_last_modified is inclusive, so the objects emitted at the
+	// watermark last run are returned again
+	emitted := make(map[int64]bool, len(state.AtWatermark))
+	for _, id := range state.AtWatermark {
+		emitted[id] = true
+	}
+
+	params := url.Values{}
+	params.Set("sort", "last_modified.asc")
+	if !state.Watermark.IsZero() {
+		params.Set("min_last_modified", state.Watermark.In(s.location()).Format(apiTimeLayout))
+	}
+
+	watermark := state.Watermark
+	atWatermark := append([]int64(nil), state.AtWatermark...)
+	err = s.fetch(service, src, params, func(raw json.RawMessage) error {
+		meta := struct {
+			ID           int64 `json:"id"`
+			LastModified Time  `json:"last_modified"`
+		}{}
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return err
+		}
+
+		modified := meta.LastModified.In(s.location())
+		if modified.Equal(state.Watermark) && emitted[meta.ID] {
+			return nil
+		}
+
+		obj, err := src.decode(raw)
+		if err != nil {
+			return err
+		}
+
+		event := SyncEvent{
+			Service:      service,
+			Type:         SyncUpdated,
+			ID:           meta.ID,
+			LastModified: modified,
+			Object:       obj,
+		}
+		if s.DetectDeletes && !state.Known[meta.ID] {
+			event.Type = SyncCreated
+			state.Known[meta.ID] = true
+		}
+
+		switch {
+		case modified.After(watermark):
+			watermark = modified
+			atWatermark = []int64{meta.ID}
+		case modified.Equal(watermark):
+			atWatermark = append(atWatermark, meta.ID)
+		}
+
+		return fn(event)
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.DetectDeletes && !state.Watermark.IsZero() {
+		if err := s.syncDeletes(service, src, state, fn); err != nil {
+			return err
+		}
+	}
+
+	state.Watermark = watermark
+	state.AtWatermark = atWatermark
+	return s.Store.Save(service, state)
+}
+
+// syncDeletes emits a deleted event for every known ID no longer returned
+func (s *Syncer) syncDeletes(service string, src syncSource, state *SyncState, fn func(SyncEvent) error) error {
+	params := url.Values{}
+	params.Set("fields", "id")
+
+	live := make(map[int64]bool)
+	err := s.fetch(service, src, params, func(raw json.RawMessage) error {
+		meta := struct {
+			ID int64 `json:"id"`
+		}{}
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return err
+		}
+
+		live[meta.ID] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0)
+	for id := range state.Known {
+		if !live[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		delete(state.Known, id)
+		if err := fn(SyncEvent{Service: service, Type: SyncDeleted, ID: id}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fetch pages through the service calling fn with each raw object
+func (s *Syncer) fetch(service string, src syncSource, params url.Values, fn func(json.RawMessage) error) error {
+	path := func(url.Values) string { return src.path(s) }
+	svc := NewService[json.RawMessage](s.client, service, src.key, path)
+
+	for raw, err := range svc.All(params) {
+		if err != nil {
+			return err
+		}
+
+		if err := fn(raw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Syncer) memberID() int {
+	if s.MemberID > 0 {
+		return s.MemberID
+	}
+
+	return s.client.MemberID
+}
+
+func (s *Syncer) location() *time.Location {
+	if s.Location != nil {
+		return s.Location
+	}
+
+	return time.UTC
+}
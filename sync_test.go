@@ -0,0 +1,143 @@
+package appnexus
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSyncer_Sync(t *testing.T) {
+	setup()
+	defer teardown()
+
+	run, listRun := 0, 0
+	mux.HandleFunc("/deal", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("fields") == "id" {
+			listRun++
+			if listRun == 1 {
+				fmt.Fprint(w, `{"response":{"status":"OK","count":3,"deals":[{"id":1},{"id":2},{"id":3}]}}`)
+				return
+			}
+			fmt.Fprint(w, `{"response":{"status":"OK","count":3,"deals":[{"id":1},{"id":3},{"id":4}]}}`)
+			return
+		}
+
+		run++
+		switch run {
+		case 1:
+			if q.Get("min_last_modified") != "" {
+				t.Errorf("first sync sent min_last_modified %q", q.Get("min_last_modified"))
+			}
+			fmt.Fprint(w, `{"response":{"status":"OK","count":2,"deals":[
+                {"id":1,"name":"one","last_modified":"2018-01-02 10:00:00"},
+                {"id":2,"name":"two","last_modified":"2018-01-03 11:30:00"}]}}`)
+		case 2:
+			if actual, expected := q.Get("min_last_modified"), "2018-01-03 11:30:00"; actual != expected {
+				t.Errorf("min_last_modified is %q, expected %q", actual, expected)
+			}
+			fmt.Fprint(w, `{"response":{"status":"OK","count":3,"deals":[
+                {"id":2,"name":"two","last_modified":"2018-01-03 11:30:00"},
+                {"id":1,"name":"one again","last_modified":"2018-01-04 09:00:00"},
+                {"id":3,"name":"three","last_modified":"2018-01-04 09:15:00"}]}}`)
+		default:
+			if actual, expected := q.Get("min_last_modified"), "2018-01-04 09:15:00"; actual != expected {
+				t.Errorf("min_last_modified is %q, expected %q", actual, expected)
+			}
+			fmt.Fprint(w, `{"response":{"status":"OK","count":2,"deals":[
+                {"id":3,"name":"three","last_modified":"2018-01-04 09:15:00"},
+                {"id":4,"name":"four","last_modified":"2018-01-04 09:15:00"}]}}`)
+		}
+	})
+
+	store := &MemorySyncStore{}
+	syncer := NewSyncer(client, store)
+	syncer.DetectDeletes = true
+
+	var events []string
+	collect := func(e SyncEvent) error {
+		events = append(events, fmt.Sprintf("%s %d", e.Type, e.ID))
+		if e.Type != SyncDeleted {
+			if _, ok := e.Object.(*Deal); !ok {
+				t.Errorf("event object is %T, expected *Deal", e.Object)
+			}
+		}
+		return nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := syncer.Sync(SyncDeals, collect); err != nil {
+			t.Fatalf("Syncer.Sync returned error: %v", err)
+		}
+	}
+
+	expected := []string{"created 1", "created 2", "updated 1", "created 3", "created 4", "deleted 2"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Syncer.Sync emitted %v, expected %v", events, expected)
+	}
+
+	state, _ := store.Load(SyncDeals)
+	if actual, expected := state.Watermark, time.Date(2018, 1, 4, 9, 15, 0, 0, time.UTC); !actual.Equal(expected) {
+		t.Errorf("Watermark is %v, expected %v", actual, expected)
+	}
+	if !reflect.DeepEqual(state.AtWatermark, []int64{3, 4}) {
+		t.Errorf("AtWatermark is %v, expected [3 4]", state.AtWatermark)
+	}
+}
+
+func TestSyncer_SyncWithoutDeletes(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/deal", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"status":"OK","count":1,"deals":[{"id":1,"last_modified":"2018-01-02 10:00:00"}]}}`)
+	})
+
+	store := &MemorySyncStore{}
+	syncer := NewSyncer(client, store)
+
+	var events []SyncEventType
+	err := syncer.Sync(SyncDeals, func(e SyncEvent) error {
+		events = append(events, e.Type)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Syncer.Sync returned error: %v", err)
+	}
+
+	if !reflect.DeepEqual(events, []SyncEventType{SyncUpdated}) {
+		t.Errorf("Syncer.Sync emitted %v, expected [updated]", events)
+	}
+
+	state, _ := store.Load(SyncDeals)
+	if len(state.Known) != 0 {
+		t.Errorf("Syncer kept known IDs %v without DetectDeletes", state.Known)
+	}
+}
+
+func TestFileSyncStore(t *testing.T) {
+	store := &FileSyncStore{Path: filepath.Join(t.TempDir(), "sync.json")}
+
+	state, err := store.Load(SyncSegments)
+	if err != nil || !state.Watermark.IsZero() {
+		t.Fatalf("FileSyncStore.Load returned %+v, %v for a missing file", state, err)
+	}
+
+	state.Watermark = time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	state.Known[7] = true
+	if err := store.Save(SyncSegments, state); err != nil {
+		t.Fatalf("FileSyncStore.Save returned error: %v", err)
+	}
+
+	actual, err := (&FileSyncStore{Path: store.Path}).Load(SyncSegments)
+	if err != nil {
+		t.Fatalf("FileSyncStore.Load returned error: %v", err)
+	}
+
+	if !actual.Watermark.Equal(state.Watermark) || !actual.Known[7] {
+		t.Errorf("FileSyncStore.Load returned %+v, expected %+v", actual, state)
+	}
+}
@@ -0,0 +1,237 @@
+package appnexus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Hosts serving AST and legacy placement tags
+const (
+	ASTLibraryURL   = "https://acdn.adnxs.com/ast/ast.js"
+	TagSecureHost   = "https://secure.adnxs.com"
+	TagInsecureHost = "http://ib.adnxs.com"
+)
+
+// TagOptions controls the tags generated for a placement
+type TagOptions struct {
+	// Sizes defaults to the placement's size and supported sizes
+	Sizes []Size
+
+	// Insecure uses the http host for legacy tags and VAST URLs
+	Insecure bool
+
+	// Keywords are passed to the ad call for targeting
+	Keywords map[string][]string
+
+	// ReservePrice defaults to the placement's reserve price
+	ReservePrice Decimal
+
+	// TargetID is the id of the div an AST tag renders into, defaulting to
+	// apn_ad_<placement ID>
+	TargetID string
+}
+
+// TagBuilder produces ready-to-paste tags for a placement on a site
+type TagBuilder struct {
+	Placement Placement
+	Site      *Site
+	Options   TagOptions
+}
+
+// NewTagBuilder returns a tag builder for the placement. site may be nil.
+func NewTagBuilder(p Placement, site *Site, opt TagOptions) *TagBuilder {
+	return &TagBuilder{Placement: p, Site: site, Options: opt}
+}
+
+// sizes returns the sizes to request, the first being the primary size
+func (b *TagBuilder) sizes() ([]Size, error) {
+	sizes := b.Options.Sizes
+	if len(sizes) == 0 {
+		if b.Placement.Width > 0 && b.Placement.Height > 0 {
+			sizes = append(sizes, Size{Width: b.Placement.Width, Height: b.Placement.Height})
+		}
+		for _, s := range b.Placement.SupportedSizes {
+			if len(sizes) == 0 || s != sizes[0] {
+				sizes = append(sizes, s)
+			}
+		}
+	}
+
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("placement %d has no size", b.Placement.ID)
+	}
+
+	return sizes, nil
+}
+
+func (b *TagBuilder) reserve() Decimal {
+	if !b.Options.ReservePrice.IsZero() {
+		return b.Options.ReservePrice
+	}
+
+	return b.Placement.ReservePrice
+}
+
+func (b *TagBuilder) targetID() string {
+	if b.Options.TargetID != "" {
+		return b.Options.TargetID
+	}
+
+	return fmt.Sprintf("apn_ad_%d", b.Placement.ID)
+}
+
+func (b *TagBuilder) host() string {
+	if b.Options.Insecure {
+		return TagInsecureHost
+	}
+
+	return TagSecureHost
+}
+
+func (b *TagBuilder) keywordNames() []string {
+	names := make([]string, 0, len(b.Options.Keywords))
+	for name := range b.Options.Keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// AST returns an AST snippet which loads ast.js, defines the tag and shows
+// it in its own div
+func (b *TagBuilder) AST() (string, error) {
+	if b.Placement.ID < 1 {
+		return "", errors.New("AST tag requires a placement ID")
+	}
+
+	sizes, err := b.sizes()
+	if err != nil {
+		return "", err
+	}
+
+	target := b.targetID()
+	list := make([]string, len(sizes))
+	for i, s := range sizes {
+		list[i] = fmt.Sprintf("[%d, %d]", s.Width, s.Height)
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "<script src=\"%s\" async></script>\n", ASTLibraryURL)
+	fmt.Fprint(buf, "<script type=\"text/javascript\">\n")
+	fmt.Fprint(buf, "  var apntag = apntag || {};\n  apntag.anq = apntag.anq || [];\n")
+	fmt.Fprint(buf, "  apntag.anq.push(function() {\n    apntag.defineTag({\n")
+	fmt.Fprintf(buf, "      tagId: %d,\n", b.Placement.ID)
+	fmt.Fprintf(buf, "      sizes: [%s],\n", strings.Join(list, ", "))
+
+	if names := b.keywordNames(); len(names) > 0 {
+		kws := make([]string, len(names))
+		for i, name := range names {
+			values := make([]string, len(b.Options.Keywords[name]))
+			for j, v := range b.Options.Keywords[name] {
+				values[j] = jsString(v)
+			}
+			kws[i] = fmt.Sprintf("%s: [%s]", jsString(name), strings.Join(values, ", "))
+		}
+		fmt.Fprintf(buf, "      keywords: {%s},\n", strings.Join(kws, ", "))
+	}
+
+	if r := b.reserve(); !r.IsZero() {
+		fmt.Fprintf(buf, "      reserve: %s,\n", r)
+	}
+
+	fmt.Fprintf(buf, "      targetId: %s\n", jsString(target))
+	fmt.Fprint(buf, "    });\n    apntag.loadTags();\n  });\n</script>\n")
+	fmt.Fprintf(buf, "<div id=\"%s\">\n  <script type=\"text/javascript\">\n", html.EscapeString(target))
+	fmt.Fprintf(buf, "    apntag.anq.push(function() { apntag.showTag(%s); });\n", jsString(target))
+	fmt.Fprint(buf, "  </script>\n</div>")
+
+	return buf.String(), nil
+}
+
+// jsString quotes s as a JavaScript string literal which is safe inside a
+// script element, as json.Marshal escapes <, > and & so that a value such as
+// "</script>" cannot close it
+func jsString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// legacyURL builds the URL of a ttj, tt or ptv tag
+func (b *TagBuilder) legacyURL(path string) (string, error) {
+	if b.Placement.ID < 1 {
+		return "", errors.New("tag requires a placement ID")
+	}
+
+	sizes, err := b.sizes()
+	if err != nil {
+		return "", err
+	}
+
+	params := []string{fmt.Sprintf("id=%d", b.Placement.ID), "size=" + sizes[0].String()}
+
+	if len(sizes) > 1 {
+		promo := make([]string, len(sizes)-1)
+		for i, s := range sizes[1:] {
+			promo[i] = s.String()
+		}
+		params = append(params, "promo_sizes="+strings.Join(promo, ","))
+	}
+
+	if r := b.reserve(); !r.IsZero() {
+		params = append(params, "reserve="+r.String())
+	}
+
+	for _, name := range b.keywordNames() {
+		for _, v := range b.Options.Keywords[name] {
+			params = append(params, "kw_"+url.QueryEscape(name)+"="+url.QueryEscape(v))
+		}
+	}
+
+	referrer := "[REFERRER_URL]"
+	if b.Site != nil && b.Site.URL != "" {
+		referrer = url.QueryEscape(b.Site.URL)
+	}
+	params = append(params, "cb=[CACHEBUSTER]", "referrer="+referrer)
+
+	return b.host() + "/" + path + "?" + strings.Join(params, "&"), nil
+}
+
+// JS returns a legacy JavaScript (ttj) tag
+func (b *TagBuilder) JS() (string, error) {
+	u, err := b.legacyURL("ttj")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("<script src=\"%s\" type=\"text/javascript\"></script>", html.EscapeString(u)), nil
+}
+
+// TinyTag returns the URL of a legacy TinyTag (tt), which serves the ad as
+// an HTML document
+func (b *TagBuilder) TinyTag() (string, error) {
+	return b.legacyURL("tt")
+}
+
+// IFrame returns a legacy iframe tag wrapping the TinyTag
+func (b *TagBuilder) IFrame() (string, error) {
+	u, err := b.legacyURL("tt")
+	if err != nil {
+		return "", err
+	}
+
+	sizes, _ := b.sizes()
+	return fmt.Sprintf("<iframe src=\"%s\" frameborder=\"0\" scrolling=\"no\" marginheight=\"0\" marginwidth=\"0\" topmargin=\"0\" leftmargin=\"0\" allowtransparency=\"true\" width=\"%d\" height=\"%d\"></iframe>",
+		html.EscapeString(u), sizes[0].Width, sizes[0].Height), nil
+}
+
+// VASTURL returns the VAST URL of a video placement
+func (b *TagBuilder) VASTURL() (string, error) {
+	return b.legacyURL("ptv")
+}
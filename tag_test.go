@@ -0,0 +1,76 @@
+package appnexus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTagBuilder_Legacy(t *testing.T) {
+	p := Placement{ID: 123, Width: 300, Height: 250, SupportedSizes: []Size{{300, 250}, {300, 600}}, ReservePrice: MustParseDecimal("0.5")}
+	site := &Site{URL: "http://news.example.com"}
+	b := NewTagBuilder(p, site, TagOptions{Keywords: map[string][]string{"section": {"sport"}}})
+
+	u, err := b.TinyTag()
+	if err != nil {
+		t.Fatalf("TinyTag returned error: %v", err)
+	}
+
+	expected := "https://secure.adnxs.com/tt?id=123&size=300x250&promo_sizes=300x600&reserve=0.5&kw_section=sport&cb=[CACHEBUSTER]&referrer=http%3A%2F%2Fnews.example.com"
+	if u != expected {
+		t.Errorf("TinyTag is %s, expected %s", u, expected)
+	}
+
+	iframe, err := b.IFrame()
+	if err != nil || !strings.Contains(iframe, `width="300" height="250"`) || !strings.Contains(iframe, "tt?id=123&amp;size=300x250") {
+		t.Errorf("IFrame returned %s, %v", iframe, err)
+	}
+
+	b.Options = TagOptions{Insecure: true, Sizes: []Size{{640, 480}}}
+	b.Site = nil
+	u, err = b.VASTURL()
+	if expected := "http://ib.adnxs.com/ptv?id=123&size=640x480&reserve=0.5&cb=[CACHEBUSTER]&referrer=[REFERRER_URL]"; err != nil || u != expected {
+		t.Errorf("VASTURL is %s, %v, expected %s", u, err, expected)
+	}
+
+	if _, err := NewTagBuilder(Placement{ID: 1}, nil, TagOptions{}).JS(); err == nil {
+		t.Errorf("JS accepted a placement without a size")
+	}
+}
+
+func TestTagBuilder_AST(t *testing.T) {
+	p := Placement{ID: 123, Width: 728, Height: 90}
+	b := NewTagBuilder(p, nil, TagOptions{Keywords: map[string][]string{"genre": {"news", "tech"}}, ReservePrice: MustParseDecimal("1.25")})
+
+	tag, err := b.AST()
+	if err != nil {
+		t.Fatalf("AST returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		`tagId: 123,`,
+		`sizes: [[728, 90]],`,
+		`keywords: {"genre": ["news", "tech"]},`,
+		`reserve: 1.25,`,
+		`targetId: "apn_ad_123"`,
+		`<div id="apn_ad_123">`,
+		`apntag.showTag("apn_ad_123");`,
+	} {
+		if !strings.Contains(tag, want) {
+			t.Errorf("AST tag is missing %s:\n%s", want, tag)
+		}
+	}
+}
+
+func TestTagBuilder_ASTEscapesKeywords(t *testing.T) {
+	p := Placement{ID: 123, Width: 728, Height: 90}
+	b := NewTagBuilder(p, nil, TagOptions{Keywords: map[string][]string{"q": {"</script><script>alert(1)</script>"}}})
+
+	tag, err := b.AST()
+	if err != nil {
+		t.Fatalf("AST returned error: %v", err)
+	}
+
+	if strings.Count(tag, "</script>") != 3 || !strings.Contains(tag, `"\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e"`) {
+		t.Errorf("AST tag does not escape keywords:\n%s", tag)
+	}
+}
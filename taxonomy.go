@@ -0,0 +1,318 @@
+package appnexus
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TaxonomySeparator separates the levels of an IAB-style taxonomy path such
+// as "Auto > SUV > Luxury"
+const TaxonomySeparator = " > "
+
+// SegmentNode is a segment within a SegmentTree
+type SegmentNode struct {
+	Segment  Segment
+	Parent   *SegmentNode
+	Children []*SegmentNode
+}
+
+// SegmentTree is the parent/child hierarchy of a member's segments.
+// Segments whose parent does not exist are listed in Orphans and treated as
+// roots. Segments on a parent cycle are listed in Cycles and left out of the
+// tree.
+type SegmentTree struct {
+	Roots   []*SegmentNode
+	Nodes   map[int64]*SegmentNode
+	Orphans []*SegmentNode
+	Cycles  [][]int64
+}
+
+// Tree loads every segment of the member and builds its hierarchy
+func (s *SegmentService) Tree(memberID int) (*SegmentTree, error) {
+	segments, err := s.ListAll(memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildSegmentTree(segments), nil
+}
+
+// BuildSegmentTree builds the hierarchy of segments from ParentSegmentID
+func BuildSegmentTree(segments []Segment) *SegmentTree {
+	t := &SegmentTree{Nodes: make(map[int64]*SegmentNode, len(segments))}
+
+	for _, seg := range segments {
+		t.Nodes[seg.ID] = &SegmentNode{Segment: seg}
+	}
+
+	onCycle := t.findCycles()
+
+	for _, id := range t.sortedIDs() {
+		node := t.Nodes[id]
+		if onCycle[id] {
+			continue
+		}
+
+		parentID := int64(node.Segment.ParentSegmentID)
+		parent, ok := t.Nodes[parentID]
+
+		switch {
+		case parentID == 0:
+			t.Roots = append(t.Roots, node)
+		case !ok || onCycle[parentID]:
+			t.Orphans = append(t.Orphans, node)
+			t.Roots = append(t.Roots, node)
+		default:
+			node.Parent = parent
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	return t
+}
+
+// findCycles records every cycle of parent links and returns the IDs on them
+func (t *SegmentTree) findCycles() map[int64]bool {
+	onCycle := make(map[int64]bool)
+	done := make(map[int64]bool)
+
+	for _, id := range t.sortedIDs() {
+		seen := make(map[int64]int)
+		var chain []int64
+
+		for cur := id; cur != 0 && !done[cur]; {
+			node, ok := t.Nodes[cur]
+			if !ok {
+				break
+			}
+
+			if i, ok := seen[cur]; ok {
+				cycle := append([]int64(nil), chain[i:]...)
+				t.Cycles = append(t.Cycles, cycle)
+				for _, c := range cycle {
+					onCycle[c] = true
+				}
+				break
+			}
+
+			seen[cur] = len(chain)
+			chain = append(chain, cur)
+			cur = int64(node.Segment.ParentSegmentID)
+		}
+
+		for _, c := range chain {
+			done[c] = true
+		}
+	}
+
+	return onCycle
+}
+
+func (t *SegmentTree) sortedIDs() []int64 {
+	ids := make([]int64, 0, len(t.Nodes))
+	for id := range t.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+// Walk visits every node in the tree depth first, parents before children
+func (t *SegmentTree) Walk(fn func(node *SegmentNode, depth int) error) error {
+	var walk func(nodes []*SegmentNode, depth int) error
+	walk = func(nodes []*SegmentNode, depth int) error {
+		for _, node := range nodes {
+			if err := fn(node, depth); err != nil {
+				return err
+			}
+
+			if err := walk(node.Children, depth+1); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return walk(t.Roots, 0)
+}
+
+// Path returns the short names from the root down to the node
+func (n *SegmentNode) Path() []string {
+	var path []string
+	for cur := n; cur != nil; cur = cur.Parent {
+		path = append([]string{cur.Segment.ShortName}, path...)
+	}
+
+	return path
+}
+
+// Paths returns the IAB-style path of every segment, such as
+// "Auto > SUV > Luxury"
+func (t *SegmentTree) Paths() []string {
+	var paths []string
+	_ = t.Walk(func(node *SegmentNode, depth int) error {
+		paths = append(paths, strings.Join(node.Path(), TaxonomySeparator))
+		return nil
+	})
+
+	return paths
+}
+
+type taxonomyJSON struct {
+	ID       int64           `json:"id"`
+	Code     string          `json:"code,omitempty"`
+	Name     string          `json:"name"`
+	Children []*taxonomyJSON `json:"children,omitempty"`
+}
+
+// WriteJSON writes the tree as nested JSON objects
+func (t *SegmentTree) WriteJSON(w io.Writer) error {
+	var convert func(nodes []*SegmentNode) []*taxonomyJSON
+	convert = func(nodes []*SegmentNode) []*taxonomyJSON {
+		out := make([]*taxonomyJSON, len(nodes))
+		for i, node := range nodes {
+			out[i] = &taxonomyJSON{
+				ID:       node.Segment.ID,
+				Code:     node.Segment.Code,
+				Name:     node.Segment.ShortName,
+				Children: convert(node.Children),
+			}
+		}
+
+		return out
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(convert(t.Roots))
+}
+
+// WriteCSV writes one row per segment with its ID, code, name, parent ID and
+// path
+func (t *SegmentTree) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "code", "name", "parent_segment_id", "path"}); err != nil {
+		return err
+	}
+
+	err := t.Walk(func(node *SegmentNode, depth int) error {
+		return cw.Write([]string{
+			strconv.FormatInt(node.Segment.ID, 10),
+			node.Segment.Code,
+			node.Segment.ShortName,
+			strconv.Itoa(node.Segment.ParentSegmentID),
+			strings.Join(node.Path(), TaxonomySeparator),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ParseTaxonomyPath splits a path such as "Auto > SUV > Luxury" into its
+// trimmed levels
+func ParseTaxonomyPath(path string) []string {
+	var levels []string
+	for _, level := range strings.Split(path, ">") {
+		if level = strings.TrimSpace(level); level != "" {
+			levels = append(levels, level)
+		}
+	}
+
+	return levels
+}
+
+// CreateTaxonomy creates the segments needed for every path, such as
+// "Auto > SUV > Luxury", reusing existing segments with the same short name
+// under the same parent. It returns the segments it created. It fails
+// before creating anything if a path goes through an existing segment whose
+// parent is unknown, as that segment's place in the taxonomy is not known.
+func (s *SegmentService) CreateTaxonomy(memberID int, paths []string) ([]Segment, error) {
+	tree, err := s.Tree(memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	// child finds the segment named name under parent, or among the roots
+	child := func(parent *SegmentNode, name string) *SegmentNode {
+		nodes := tree.Roots
+		if parent != nil {
+			nodes = parent.Children
+		}
+
+		for _, node := range nodes {
+			if strings.EqualFold(node.Segment.ShortName, name) {
+				return node
+			}
+		}
+		return nil
+	}
+
+	orphans := make(map[*SegmentNode]bool, len(tree.Orphans))
+	for _, node := range tree.Orphans {
+		orphans[node] = true
+	}
+
+	for _, path := range paths {
+		var parent *SegmentNode
+		for _, name := range ParseTaxonomyPath(path) {
+			next := child(parent, name)
+			if next == nil {
+				break
+			}
+
+			if orphans[next] {
+				orphan := next.Segment
+				return nil, fmt.Errorf("CreateTaxonomy: path %q goes through segment %d, which has unknown parent %d", path, orphan.ID, orphan.ParentSegmentID)
+			}
+			parent = next
+		}
+	}
+
+	var created []Segment
+	for _, path := range paths {
+		levels := ParseTaxonomyPath(path)
+		if len(levels) == 0 {
+			return created, errors.New("CreateTaxonomy: empty path")
+		}
+
+		var parent *SegmentNode
+		for _, name := range levels {
+			next := child(parent, name)
+			if next == nil {
+				seg := Segment{ShortName: name, MemberID: memberID, Active: true}
+				if parent != nil {
+					seg.ParentSegmentID = int(parent.Segment.ID)
+				}
+
+				if _, err := s.Add(memberID, &seg); err != nil {
+					return created, err
+				}
+				created = append(created, seg)
+
+				next = &SegmentNode{Segment: seg, Parent: parent}
+				tree.Nodes[seg.ID] = next
+				if parent == nil {
+					tree.Roots = append(tree.Roots, next)
+				} else {
+					parent.Children = append(parent.Children, next)
+				}
+			}
+
+			parent = next
+		}
+	}
+
+	return created, nil
+}
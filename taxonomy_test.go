@@ -0,0 +1,123 @@
+package appnexus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBuildSegmentTree(t *testing.T) {
+	tree := BuildSegmentTree([]Segment{
+		{ID: 1, ShortName: "Auto"},
+		{ID: 2, ShortName: "SUV", ParentSegmentID: 1},
+		{ID: 3, ShortName: "Luxury", ParentSegmentID: 2},
+		{ID: 4, ShortName: "Lost", ParentSegmentID: 99},
+		{ID: 5, ShortName: "Loop A", ParentSegmentID: 6},
+		{ID: 6, ShortName: "Loop B", ParentSegmentID: 5},
+	})
+
+	expected := []string{"Auto", "Auto > SUV", "Auto > SUV > Luxury", "Lost"}
+	if actual := tree.Paths(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Paths returned %v, expected %v", actual, expected)
+	}
+
+	if len(tree.Orphans) != 1 || tree.Orphans[0].Segment.ID != 4 {
+		t.Errorf("Orphans is %v, expected segment 4", tree.Orphans)
+	}
+
+	if !reflect.DeepEqual(tree.Cycles, [][]int64{{5, 6}}) {
+		t.Errorf("Cycles is %v, expected [[5 6]]", tree.Cycles)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tree.WriteCSV(buf); err != nil {
+		t.Fatalf("WriteCSV returned error: %v", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte("3,,Luxury,2,Auto > SUV > Luxury\n")) {
+		t.Errorf("WriteCSV wrote\n%s", buf)
+	}
+}
+
+func TestSegmentService_CreateTaxonomy(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var created []Segment
+	mux.HandleFunc("/segment/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{"response":{"status":"OK","count":1,"segments":[{"id":1,"short_name":"Auto"}]}}`)
+			return
+		}
+
+		data, _ := ioutil.ReadAll(r.Body)
+		body := struct {
+			Segment Segment `json:"segment"`
+		}{}
+		json.Unmarshal(data, &body)
+		created = append(created, body.Segment)
+		fmt.Fprintf(w, `{"response":{"status":"OK","id":%d}}`, 10+len(created))
+	})
+
+	segments, err := client.Segments.CreateTaxonomy(1, []string{"Auto > SUV > Luxury", "auto > SUV > Compact"})
+	if err != nil {
+		t.Fatalf("CreateTaxonomy returned error: %v", err)
+	}
+
+	actual := make([]string, len(created))
+	for i, seg := range created {
+		actual[i] = fmt.Sprintf("%s/%d", seg.ShortName, seg.ParentSegmentID)
+	}
+
+	expected := []string{"SUV/1", "Luxury/11", "Compact/11"}
+	if !reflect.DeepEqual(actual, expected) || len(segments) != 3 || segments[2].ID != 13 {
+		t.Errorf("CreateTaxonomy created %v, expected %v", actual, expected)
+	}
+}
+
+func TestSegmentService_CreateTaxonomyUnknownParent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/segment/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("CreateTaxonomy sent %s with an unknown parent", r.Method)
+		}
+		fmt.Fprint(w, `{"response":{"status":"OK","count":1,"segments":[{"id":2,"short_name":"Auto","parent_segment_id":99}]}}`)
+	})
+
+	if _, err := client.Segments.CreateTaxonomy(1, []string{"Auto > SUV"}); err == nil {
+		t.Errorf("CreateTaxonomy accepted a segment with an unknown parent")
+	}
+}
+
+func TestSegmentService_CreateTaxonomyUnrelatedOrphan(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var created []string
+	mux.HandleFunc("/segment/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{"response":{"status":"OK","count":2,"segments":[
+				{"id":1,"short_name":"Travel"},
+				{"id":2,"short_name":"Auto","parent_segment_id":99}]}}`)
+			return
+		}
+
+		data, _ := ioutil.ReadAll(r.Body)
+		created = append(created, string(data))
+		fmt.Fprintf(w, `{"response":{"status":"OK","id":%d}}`, 10+len(created))
+	})
+
+	segments, err := client.Segments.CreateTaxonomy(1, []string{"Travel > Beach"})
+	if err != nil {
+		t.Fatalf("CreateTaxonomy returned error: %v", err)
+	}
+	if len(segments) != 1 || segments[0].ShortName != "Beach" || segments[0].ParentSegmentID != 1 {
+		t.Errorf("CreateTaxonomy created %+v, expected Beach under Travel", segments)
+	}
+}
@@ -0,0 +1,400 @@
+package appnexus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiTimeLayout is the format AppNexus uses for timestamps such as
+// last_modified and min_last_modified
+const apiTimeLayout = "2006-01-02 15:04:05"
+
+// Time is an AppNexus "YYYY-MM-DD HH:MM:SS" timestamp. The API sends wall
+// clock values without a zone, so Time keeps the wall clock and In reads it in
+// a given timezone, usually the member's. The zero Time is sent as null.
+type Time struct {
+	wall time.Time
+}
+
+// NewTime returns the Time for t as a wall clock in loc. A nil loc keeps the
+// wall clock of t as it is.
+func NewTime(t time.Time, loc *time.Location) Time {
+	if loc != nil {
+		t = t.In(loc)
+	}
+
+	y, mo, d := t.Date()
+	h, mi, s := t.Clock()
+	return Time{wall: time.Date(y, mo, d, h, mi, s, 0, time.UTC)}
+}
+
+// ParseTime parses an AppNexus timestamp. The empty string is the zero Time.
+func ParseTime(value string) (Time, error) {
+	if value == "" {
+		return Time{}, nil
+	}
+
+	t, err := time.Parse(apiTimeLayout, value)
+	if err != nil {
+		return Time{}, errors.New("ParseTime: " + err.Error())
+	}
+
+	return Time{wall: t}, nil
+}
+
+// MustParseTime is like ParseTime but panics on error
+func MustParseTime(value string) Time {
+	t, err := ParseTime(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+// In returns the wall clock read in loc
+func (t Time) In(loc *time.Location) time.Time {
+	if t.IsZero() {
+		return time.Time{}
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	y, mo, d := t.wall.Date()
+	h, mi, s := t.wall.Clock()
+	return time.Date(y, mo, d, h, mi, s, 0, loc)
+}
+
+// IsZero reports whether t is unset
+func (t Time) IsZero() bool {
+	return t.wall.IsZero()
+}
+
+// Equal reports whether t and u hold the same wall clock
+func (t Time) Equal(u Time) bool {
+	return t.wall.Equal(u.wall)
+}
+
+// Before reports whether the wall clock of t is before that of u
+func (t Time) Before(u Time) bool {
+	return t.wall.Before(u.wall)
+}
+
+// After reports whether the wall clock of t is after that of u
+func (t Time) After(u Time) bool {
+	return t.wall.After(u.wall)
+}
+
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.wall.Format(apiTimeLayout)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (t Time) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (t *Time) UnmarshalText(data []byte) error {
+	parsed, err := ParseTime(string(data))
+	if err != nil {
+		return err
+	}
+
+	*t = parsed
+	return nil
+}
+
+// MarshalJSON sends the zero Time as null
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return []byte(strconv.Quote(t.String())), nil
+}
+
+// UnmarshalJSON accepts null and "" as the zero Time
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = Time{}
+		return nil
+	}
+
+	value, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("Time: cannot unmarshal %s", data)
+	}
+
+	return t.UnmarshalText([]byte(value))
+}
+
+// decimalPlaces is the precision kept by Decimal
+const decimalPlaces = 6
+
+const decimalScale = 1000000
+
+// Decimal is a fixed point number with six decimal places, used for prices so
+// that they do not suffer float rounding
+type Decimal struct {
+	micros int64
+}
+
+// NewDecimal returns the Decimal for a whole number of units plus micros
+// millionths
+func NewDecimal(units int64, micros int64) Decimal {
+	return Decimal{micros: units*decimalScale + micros}
+}
+
+// DecimalFromFloat rounds f to six decimal places
+func DecimalFromFloat(f float64) Decimal {
+	return Decimal{micros: int64(math.Round(f * decimalScale))}
+}
+
+// ParseDecimal parses a decimal string such as "1.25" or "-0.000001". Values
+// with more than six decimal places are rejected rather than rounded.
+func ParseDecimal(value string) (Decimal, error) {
+	return parseDecimal(value, false)
+}
+
+// parseDecimal parses value, rounding extra decimal places half away from
+// zero if round is set and rejecting them otherwise
+func parseDecimal(value string, round bool) (Decimal, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return Decimal{}, errors.New("ParseDecimal: empty value")
+	}
+
+	neg := false
+	if s[0] == '-' || s[0] == '+' {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+
+	roundUp := false
+	if len(frac) > decimalPlaces {
+		if !round {
+			return Decimal{}, fmt.Errorf("ParseDecimal: %q has more than %d decimal places", value, decimalPlaces)
+		}
+
+		if strings.Trim(frac, "0123456789") != "" {
+			return Decimal{}, fmt.Errorf("ParseDecimal: invalid value %q", value)
+		}
+		roundUp = frac[decimalPlaces] >= '5'
+		frac = frac[:decimalPlaces]
+	}
+
+	if whole == "" && frac == "" {
+		return Decimal{}, fmt.Errorf("ParseDecimal: invalid value %q", value)
+	}
+
+	if whole == "" {
+		whole = "0"
+	}
+
+	units, err := strconv.ParseUint(whole, 10, 63)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("ParseDecimal: invalid value %q", value)
+	}
+	if units > (math.MaxInt64-decimalScale)/decimalScale {
+		return Decimal{}, fmt.Errorf("ParseDecimal: %q is out of range", value)
+	}
+
+	var micros uint64
+	if frac != "" {
+		micros, err = strconv.ParseUint(frac+strings.Repeat("0", decimalPlaces-len(frac)), 10, 63)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("ParseDecimal: invalid value %q", value)
+		}
+	}
+
+	d := NewDecimal(int64(units), int64(micros))
+	if roundUp {
+		d.micros++
+	}
+	if neg {
+		d.micros = -d.micros
+	}
+
+	return d, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on error
+func MustParseDecimal(value string) Decimal {
+	d, err := ParseDecimal(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// Add returns d + e, failing if the sum is out of range
+func (d Decimal) Add(e Decimal) (Decimal, error) {
+	s := d.micros + e.micros
+	if (e.micros > 0 && s < d.micros) || (e.micros < 0 && s > d.micros) {
+		return Decimal{}, fmt.Errorf("Decimal: %s + %s overflows", d, e)
+	}
+
+	return Decimal{micros: s}, nil
+}
+
+// Sub returns d - e, failing if the difference is out of range
+func (d Decimal) Sub(e Decimal) (Decimal, error) {
+	s := d.micros - e.micros
+	if (e.micros > 0 && s > d.micros) || (e.micros < 0 && s < d.micros) {
+		return Decimal{}, fmt.Errorf("Decimal: %s - %s overflows", d, e)
+	}
+
+	return Decimal{micros: s}, nil
+}
+
+// Mul returns d * e rounded half away from zero to six decimal places,
+// failing if the product is out of range
+func (d Decimal) Mul(e Decimal) (Decimal, error) {
+	p := new(big.Int).Mul(big.NewInt(d.micros), big.NewInt(e.micros))
+	q, r := new(big.Int).QuoRem(p, big.NewInt(decimalScale), new(big.Int))
+
+	half := int64(decimalScale / 2)
+	if r.Int64() >= half {
+		q.Add(q, big.NewInt(1))
+	} else if r.Int64() <= -half {
+		q.Sub(q, big.NewInt(1))
+	}
+
+	if !q.IsInt64() {
+		return Decimal{}, fmt.Errorf("Decimal: %s * %s overflows", d, e)
+	}
+
+	return Decimal{micros: q.Int64()}, nil
+}
+
+// MulInt returns d * n, failing if the product is out of range
+func (d Decimal) MulInt(n int64) (Decimal, error) {
+	p := d.micros * n
+	if d.micros != 0 && (p/d.micros != n || (d.micros == -1 && n == math.MinInt64) || (n == -1 && d.micros == math.MinInt64)) {
+		return Decimal{}, fmt.Errorf("Decimal: %s * %d overflows", d, n)
+	}
+
+	return Decimal{micros: p}, nil
+}
+
+// Cmp returns -1, 0 or +1 as d is less than, equal to or greater than e
+func (d Decimal) Cmp(e Decimal) int {
+	switch {
+	case d.micros < e.micros:
+		return -1
+	case d.micros > e.micros:
+		return 1
+	}
+
+	return 0
+}
+
+// IsZero reports whether d is zero
+func (d Decimal) IsZero() bool {
+	return d.micros == 0
+}
+
+// Float64 returns the nearest float64 to d
+func (d Decimal) Float64() float64 {
+	return float64(d.micros) / decimalScale
+}
+
+// String formats d without trailing zeros, such as "1.5" or "2"
+func (d Decimal) String() string {
+	m := d.micros
+	sign := ""
+	if m < 0 {
+		sign = "-"
+		m = -m
+	}
+
+	s := fmt.Sprintf("%s%d", sign, m/decimalScale)
+	if frac := m % decimalScale; frac != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%06d", frac), "0")
+	}
+
+	return s
+}
+
+// MarshalJSON sends d as a JSON number
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number, a quoted number or null. Numbers with
+// more than six decimal places are rounded half away from zero.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*d = Decimal{}
+		return nil
+	}
+
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("Decimal: cannot unmarshal %s", data)
+		}
+		*d = DecimalFromFloat(f)
+		return nil
+	}
+
+	parsed, err := parseDecimal(s, true)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
+
+// Money is an amount in a currency, such as a deal floor price
+type Money struct {
+	Amount   Decimal
+	Currency string
+}
+
+// Add returns m + n, failing if the currencies differ
+func (m Money) Add(n Money) (Money, error) {
+	if m.Currency != n.Currency {
+		return Money{}, fmt.Errorf("Money: cannot add %s to %s", n.Currency, m.Currency)
+	}
+
+	amount, err := m.Amount.Add(n.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return Money{Amount: amount, Currency: m.Currency}, nil
+}
+
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Amount.String()
+	}
+
+	return m.Amount.String() + " " + m.Currency
+}
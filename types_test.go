@@ -0,0 +1,179 @@
+package appnexus
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTime_JSON(t *testing.T) {
+	deal := Deal{}
+	err := json.Unmarshal([]byte(`{"id":1,"start_date":"2018-03-04 05:06:07","end_date":null}`), &deal)
+	if err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+
+	if actual, expected := deal.StartDate.String(), "2018-03-04 05:06:07"; actual != expected {
+		t.Errorf("StartDate is %q, expected %q", actual, expected)
+	}
+
+	if !deal.EndDate.IsZero() {
+		t.Errorf("EndDate is %v, expected the zero Time", deal.EndDate)
+	}
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+
+	if actual, expected := deal.StartDate.In(ny), time.Date(2018, 3, 4, 5, 6, 7, 0, ny); !actual.Equal(expected) {
+		t.Errorf("StartDate.In is %v, expected %v", actual, expected)
+	}
+
+	if actual := NewTime(time.Date(2018, 3, 4, 10, 6, 7, 0, time.UTC), ny); !actual.Equal(deal.StartDate) {
+		t.Errorf("NewTime is %v, expected %v", actual, deal.StartDate)
+	}
+
+	data, _ := json.Marshal(struct {
+		Set   Time `json:"set"`
+		Null  Time `json:"null"`
+		Unset Time `json:"unset,omitzero"`
+	}{Set: deal.StartDate})
+	if actual, expected := string(data), `{"set":"2018-03-04 05:06:07","null":null}`; actual != expected {
+		t.Errorf("Marshal returned %s, expected %s", actual, expected)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"1.5", "1.5"},
+		{"0.10", "0.1"},
+		{"-0.000001", "-0.000001"},
+		{"12", "12"},
+		{".25", "0.25"},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDecimal(test.in)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q) returned error: %v", test.in, err)
+			continue
+		}
+
+		if actual := d.String(); actual != test.out {
+			t.Errorf("ParseDecimal(%q) is %s, expected %s", test.in, actual, test.out)
+		}
+	}
+
+	for _, in := range []string{"", "1.0000001", "abc", "-", "99999999999999999"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) did not return an error", in)
+		}
+	}
+
+	// Decoding rounds where ParseDecimal rejects
+	decoded := []struct {
+		in  string
+		out string
+	}{
+		{`1.0000004`, "1"},
+		{`1.0000005`, "1.000001"},
+		{`"-0.12345678"`, "-0.123457"},
+	}
+	for _, test := range decoded {
+		var d Decimal
+		if err := json.Unmarshal([]byte(test.in), &d); err != nil {
+			t.Errorf("Unmarshal(%s) returned error: %v", test.in, err)
+			continue
+		}
+
+		if actual := d.String(); actual != test.out {
+			t.Errorf("Unmarshal(%s) is %s, expected %s", test.in, actual, test.out)
+		}
+	}
+
+	if product, err := MustParseDecimal("1.5").MulInt(4); err != nil || product.String() != "6" {
+		t.Errorf("1.5 * 4 is %s, %v, expected 6", product, err)
+	}
+	if _, err := MustParseDecimal("1000000000000").MulInt(1000000000); err == nil {
+		t.Errorf("MulInt did not report an overflow")
+	}
+
+	if sum, err := MustParseDecimal("0.1").Add(MustParseDecimal("0.2")); err != nil || sum.Cmp(MustParseDecimal("0.3")) != 0 {
+		t.Errorf("0.1 + 0.2 is %s, %v, expected 0.3", sum, err)
+	}
+
+	if product, err := MustParseDecimal("2.5").Mul(MustParseDecimal("0.333333")); err != nil || product.String() != "0.833333" {
+		t.Errorf("2.5 * 0.333333 is %s, %v, expected 0.833333", product, err)
+	}
+}
+
+func TestDecimal_Overflow(t *testing.T) {
+	max := Decimal{micros: math.MaxInt64}
+	min := Decimal{micros: math.MinInt64}
+	micro := Decimal{micros: 1}
+
+	if _, err := max.Add(micro); err == nil {
+		t.Errorf("Add did not report an overflow past the maximum")
+	}
+	if _, err := min.Add(Decimal{micros: -1}); err == nil {
+		t.Errorf("Add did not report an overflow past the minimum")
+	}
+	if sum, err := max.Add(Decimal{micros: -1}); err != nil || sum.micros != math.MaxInt64-1 {
+		t.Errorf("max + -0.000001 is %s, %v", sum, err)
+	}
+
+	if _, err := min.Sub(micro); err == nil {
+		t.Errorf("Sub did not report an overflow past the minimum")
+	}
+	if _, err := max.Sub(Decimal{micros: -1}); err == nil {
+		t.Errorf("Sub did not report an overflow past the maximum")
+	}
+	if _, err := (Decimal{}).Sub(min); err == nil {
+		t.Errorf("Sub did not report an overflow negating the minimum")
+	}
+	if diff, err := max.Sub(max); err != nil || !diff.IsZero() {
+		t.Errorf("max - max is %s, %v", diff, err)
+	}
+
+	if _, err := max.Mul(NewDecimal(2, 0)); err == nil {
+		t.Errorf("Mul did not report an overflow")
+	}
+	if _, err := min.Mul(NewDecimal(-1, 0)); err == nil {
+		t.Errorf("Mul did not report an overflow negating the minimum")
+	}
+	if product, err := max.Mul(NewDecimal(1, 0)); err != nil || product != max {
+		t.Errorf("max * 1 is %s, %v", product, err)
+	}
+	if product, err := max.Mul(MustParseDecimal("0.5")); err != nil || product.micros != math.MaxInt64/2+1 {
+		t.Errorf("max * 0.5 is %s, %v", product, err)
+	}
+
+	if _, err := (Money{Amount: max, Currency: "USD"}).Add(Money{Amount: micro, Currency: "USD"}); err == nil {
+		t.Errorf("Money.Add did not report an overflow")
+	}
+}
+
+func TestDeal_FloorPrice(t *testing.T) {
+	deal := Deal{}
+	if err := json.Unmarshal([]byte(`{"floor_price":0.7,"currency":"EUR"}`), &deal); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+
+	if actual, expected := deal.Floor().String(), "0.7 EUR"; actual != expected {
+		t.Errorf("Floor is %s, expected %s", actual, expected)
+	}
+
+	if _, err := deal.Floor().Add(Money{Amount: NewDecimal(1, 0), Currency: "USD"}); err == nil {
+		t.Errorf("Money.Add accepted mixed currencies")
+	}
+
+	data, _ := json.Marshal(Deal{Code: "d", FloorPrice: MustParseDecimal("1.05")})
+	if actual, expected := string(data), `{"floor_price":1.05,"code":"d","name":"","active":false}`; actual != expected {
+		t.Errorf("Marshal returned %s, expected %s", actual, expected)
+	}
+}